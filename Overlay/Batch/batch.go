@@ -0,0 +1,77 @@
+package Batch
+
+// Mode is how the vertices of a Call are assembled.
+type Mode byte
+
+const (
+	Triangles Mode = iota
+	Lines
+)
+
+// Vertex is a point already transformed to screen space. Its memory layout,
+// eight float32 values, is the vertex format of the GL renderer.
+type Vertex struct {
+	X, Y  float32
+	U, V  float32
+	Color [4]float32
+}
+
+// Effect changes how the texture of a Call is read. The zero Effect
+// multiplies the vertex color by the texel. With SDF set the alpha of the
+// texture is a signed distance field with the outline at 0.5: Smoothing is
+// half the width of the antialiased edge, OutlineWidth how far an outline
+// reaches outside it and ShadowSoftness how far a shadow fades, all in
+// distance units. ShadowOffset is in texture coordinates.
+type Effect struct {
+	SDF            bool
+	Smoothing      float32
+	OutlineWidth   float32
+	OutlineColor   [4]float32
+	ShadowOffset   [2]float32
+	ShadowSoftness float32
+	ShadowColor    [4]float32
+}
+
+// Call is a single draw call: Count vertices starting at First, drawn with
+// Mode, Texture and Effect. Texture 0 means the vertices are not textured.
+type Call struct {
+	Mode         Mode
+	Texture      uint32
+	Effect       Effect
+	First, Count int
+}
+
+// Batch collects the vertices of a whole frame. Consecutive primitives with
+// the same mode, texture and effect extend the last Call, so a new Call, a
+// flush, only happens when one of them changes.
+type Batch struct {
+	Vertices []Vertex
+	Calls    []Call
+}
+
+// Reset empties the batch but keeps its memory for the next frame.
+func (b *Batch) Reset() {
+	b.Vertices = b.Vertices[:0]
+	b.Calls = b.Calls[:0]
+}
+
+// Triangles adds a triangle list, three vertices per triangle.
+func (b *Batch) Triangles(Texture uint32, Effect Effect, Vertices ...Vertex) {
+	b.add(Triangles, Texture, Effect, Vertices)
+}
+
+// Lines adds a line list, two vertices per line.
+func (b *Batch) Lines(Texture uint32, Effect Effect, Vertices ...Vertex) {
+	b.add(Lines, Texture, Effect, Vertices)
+}
+
+func (b *Batch) add(Mode Mode, Texture uint32, Effect Effect, Vertices []Vertex) {
+	if len(Vertices) == 0 {
+		return
+	}
+	if n := len(b.Calls); n == 0 || b.Calls[n-1].Mode != Mode || b.Calls[n-1].Texture != Texture || b.Calls[n-1].Effect != Effect {
+		b.Calls = append(b.Calls, Call{Mode: Mode, Texture: Texture, Effect: Effect, First: len(b.Vertices)})
+	}
+	b.Vertices = append(b.Vertices, Vertices...)
+	b.Calls[len(b.Calls)-1].Count += len(Vertices)
+}
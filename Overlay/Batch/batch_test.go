@@ -0,0 +1,100 @@
+package Batch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func vertices(N int) []Vertex {
+	out := make([]Vertex, N)
+	for i := range out {
+		out[i] = Vertex{X: float32(i), Y: float32(2 * i), Color: [4]float32{1, 1, 1, 1}}
+	}
+	return out
+}
+
+func TestVertices(t *testing.T) {
+	var b Batch
+	tri := vertices(3)
+	line := vertices(2)
+	b.Triangles(0, Effect{}, tri...)
+	b.Lines(0, Effect{}, line...)
+
+	want := append(append([]Vertex{}, tri...), line...)
+	if !reflect.DeepEqual(b.Vertices, want) {
+		t.Fatalf("vertices = %v, want %v", b.Vertices, want)
+	}
+	calls := []Call{
+		{Mode: Triangles, First: 0, Count: 3},
+		{Mode: Lines, First: 3, Count: 2},
+	}
+	if !reflect.DeepEqual(b.Calls, calls) {
+		t.Fatalf("calls = %+v, want %+v", b.Calls, calls)
+	}
+}
+
+func TestCalls(t *testing.T) {
+	sdf := Effect{SDF: true, Smoothing: 0.1}
+	tests := []struct {
+		name string
+		add  func(b *Batch)
+		want []Call
+	}{
+		{"same state merges", func(b *Batch) {
+			b.Triangles(1, Effect{}, vertices(3)...)
+			b.Triangles(1, Effect{}, vertices(6)...)
+		}, []Call{{Mode: Triangles, Texture: 1, First: 0, Count: 9}}},
+		{"texture change", func(b *Batch) {
+			b.Triangles(1, Effect{}, vertices(3)...)
+			b.Triangles(2, Effect{}, vertices(3)...)
+		}, []Call{
+			{Mode: Triangles, Texture: 1, First: 0, Count: 3},
+			{Mode: Triangles, Texture: 2, First: 3, Count: 3},
+		}},
+		{"mode change", func(b *Batch) {
+			b.Lines(0, Effect{}, vertices(2)...)
+			b.Triangles(0, Effect{}, vertices(3)...)
+			b.Lines(0, Effect{}, vertices(2)...)
+		}, []Call{
+			{Mode: Lines, First: 0, Count: 2},
+			{Mode: Triangles, First: 2, Count: 3},
+			{Mode: Lines, First: 5, Count: 2},
+		}},
+		{"effect change", func(b *Batch) {
+			b.Triangles(1, Effect{}, vertices(3)...)
+			b.Triangles(1, sdf, vertices(3)...)
+			b.Triangles(1, sdf, vertices(3)...)
+		}, []Call{
+			{Mode: Triangles, Texture: 1, First: 0, Count: 3},
+			{Mode: Triangles, Texture: 1, Effect: sdf, First: 3, Count: 6},
+		}},
+		{"empty adds nothing", func(b *Batch) {
+			b.Triangles(1, Effect{})
+			b.Lines(0, Effect{}, vertices(2)...)
+			b.Triangles(2, Effect{})
+			b.Lines(0, Effect{}, vertices(2)...)
+		}, []Call{{Mode: Lines, First: 0, Count: 4}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b Batch
+			test.add(&b)
+			if !reflect.DeepEqual(b.Calls, test.want) {
+				t.Fatalf("calls = %+v, want %+v", b.Calls, test.want)
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	var b Batch
+	b.Triangles(1, Effect{}, vertices(3)...)
+	b.Reset()
+	if len(b.Vertices) != 0 || len(b.Calls) != 0 {
+		t.Fatalf("after Reset: %d vertices, %d calls", len(b.Vertices), len(b.Calls))
+	}
+	b.Triangles(1, Effect{}, vertices(3)...)
+	if want := []Call{{Mode: Triangles, Texture: 1, First: 0, Count: 3}}; !reflect.DeepEqual(b.Calls, want) {
+		t.Fatalf("calls = %+v, want %+v", b.Calls, want)
+	}
+}
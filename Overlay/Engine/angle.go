@@ -0,0 +1,23 @@
+package Engine
+
+import "math"
+
+// Angle is a rotation stored in radians. Build one with Deg or Rad so the
+// unit is always explicit at the call site.
+type Angle float32
+
+func Deg(Degrees float32) Angle {
+	return Angle(Degrees * math.Pi / 180)
+}
+
+func Rad(Radians float32) Angle {
+	return Angle(Radians)
+}
+
+func (a Angle) Degrees() float32 {
+	return float32(a) * 180 / math.Pi
+}
+
+func (a Angle) Radians() float32 {
+	return float32(a)
+}
@@ -1,4 +1,4 @@
 package Engine
 
-const VertexSize int = 2 + 2 // X, Y | U, V
+const VertexSize int = 2 + 2 + 4 // X, Y | U, V | R, G, B, A
 const FloatSize int = 4
@@ -0,0 +1,73 @@
+package Engine
+
+import "math"
+
+// Transform is a 2D affine matrix
+//
+//	| A C E |
+//	| B D F |
+//	| 0 0 1 |
+//
+// Methods return a new Transform with the operation applied before t, so
+// chained calls nest like a canvas API: t.Translate(10, 0).Scale(2, 2)
+// scales around the translated origin.
+type Transform struct {
+	A, B, C, D, E, F float32
+}
+
+func Identity() Transform {
+	return Transform{A: 1, D: 1}
+}
+
+// Multiply returns t * Other, that is Other applied first.
+func (t Transform) Multiply(Other Transform) Transform {
+	return Transform{
+		A: t.A*Other.A + t.C*Other.B,
+		B: t.B*Other.A + t.D*Other.B,
+		C: t.A*Other.C + t.C*Other.D,
+		D: t.B*Other.C + t.D*Other.D,
+		E: t.A*Other.E + t.C*Other.F + t.E,
+		F: t.B*Other.E + t.D*Other.F + t.F,
+	}
+}
+
+func (t Transform) Translate(X, Y float32) Transform {
+	return t.Multiply(Transform{A: 1, D: 1, E: X, F: Y})
+}
+
+// Rotate rotates by Angle, clockwise on screen since Y points down.
+func (t Transform) Rotate(Angle Angle) Transform {
+	sin, cos := math.Sincos(float64(Angle.Radians()))
+	return t.Multiply(Transform{A: float32(cos), B: float32(sin), C: float32(-sin), D: float32(cos)})
+}
+
+func (t Transform) Scale(X, Y float32) Transform {
+	return t.Multiply(Transform{A: X, D: Y})
+}
+
+// Skew shears along X by the angle X and along Y by the angle Y.
+func (t Transform) Skew(X, Y Angle) Transform {
+	return t.Multiply(Transform{A: 1, B: float32(math.Tan(float64(Y.Radians()))), C: float32(math.Tan(float64(X.Radians()))), D: 1})
+}
+
+// Invert returns the inverse of t, or false when t collapses the plane and
+// has no inverse.
+func (t Transform) Invert() (Transform, bool) {
+	det := t.A*t.D - t.B*t.C
+	if det == 0 {
+		return Transform{}, false
+	}
+	return Transform{
+		A: t.D / det,
+		B: -t.B / det,
+		C: -t.C / det,
+		D: t.A / det,
+		E: (t.C*t.F - t.D*t.E) / det,
+		F: (t.B*t.E - t.A*t.F) / det,
+	}, true
+}
+
+// Apply maps the point X, Y through t.
+func (t Transform) Apply(X, Y float32) (float32, float32) {
+	return t.A*X + t.C*Y + t.E, t.B*X + t.D*Y + t.F
+}
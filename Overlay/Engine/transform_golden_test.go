@@ -0,0 +1,25 @@
+package Engine_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestTransformGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "transform", Width: 64, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			app.Translate(32, 32)
+			app.With(func() {
+				app.Scale(2, 1)
+				app.Skew(0.3, 0)
+				app.SetColor(255, 128, 0, 255)
+				app.DrawRect(-8, -8, 16, 16)
+			})
+			app.SetColor(0, 128, 255, 255)
+			app.DrawLine(-24, 20, 24, 20)
+			app.DrawPolygon(-4, -28, 4, -28, 0, -20)
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package Engine
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func nearTransform(a, b Transform) bool {
+	return near(a.A, b.A) && near(a.B, b.B) && near(a.C, b.C) && near(a.D, b.D) && near(a.E, b.E) && near(a.F, b.F)
+}
+
+func TestMultiplyOrder(t *testing.T) {
+	move := Identity().Translate(10, 0)
+	grow := Identity().Scale(2, 3)
+	// Multiply applies its argument first.
+	if x, y := move.Multiply(grow).Apply(1, 1); x != 12 || y != 3 {
+		t.Errorf("move * grow maps 1,1 to %g,%g, want 12,3", x, y)
+	}
+	if x, y := grow.Multiply(move).Apply(1, 1); x != 22 || y != 3 {
+		t.Errorf("grow * move maps 1,1 to %g,%g, want 22,3", x, y)
+	}
+	// Chained calls nest: the scale happens around the translated origin.
+	if chained := Identity().Translate(10, 0).Scale(2, 3); chained != move.Multiply(grow) {
+		t.Errorf("Translate then Scale = %+v, want %+v", chained, move.Multiply(grow))
+	}
+	if product := move.Multiply(Identity()); product != move {
+		t.Errorf("t * Identity = %+v, want %+v", product, move)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// Y points down, so a positive angle turns clockwise on screen.
+	x, y := Identity().Rotate(Deg(90)).Apply(1, 0)
+	if !near(x, 0) || !near(y, 1) {
+		t.Errorf("Rotate(90) maps 1,0 to %g,%g, want 0,1", x, y)
+	}
+}
+
+func TestSkew(t *testing.T) {
+	tests := []struct {
+		x, y         Angle
+		px, py       float32
+		wantX, wantY float32
+	}{
+		{Deg(45), 0, 0, 2, 2, 2},
+		{Deg(45), 0, 3, 0, 3, 0},
+		{0, Deg(45), 2, 0, 2, 2},
+		{0, Deg(45), 0, 3, 0, 3},
+		{Deg(-45), Deg(45), 1, 1, 0, 2},
+	}
+	for _, test := range tests {
+		x, y := Identity().Skew(test.x, test.y).Apply(test.px, test.py)
+		if !near(x, test.wantX) || !near(y, test.wantY) {
+			t.Errorf("Skew(%g°, %g°) maps %g,%g to %g,%g, want %g,%g",
+				test.x.Degrees(), test.y.Degrees(), test.px, test.py, x, y, test.wantX, test.wantY)
+		}
+	}
+}
+
+func TestInvert(t *testing.T) {
+	transforms := []Transform{
+		Identity(),
+		Identity().Translate(10, -4),
+		Identity().Translate(3, 7).Rotate(Deg(30)).Scale(2, 0.5).Skew(Deg(20), 0),
+	}
+	for _, transform := range transforms {
+		inverse, ok := transform.Invert()
+		if !ok {
+			t.Errorf("%+v has no inverse", transform)
+			continue
+		}
+		if product := transform.Multiply(inverse); !nearTransform(product, Identity()) {
+			t.Errorf("%+v times its inverse = %+v", transform, product)
+		}
+		x, y := transform.Apply(5, -2)
+		if x, y = inverse.Apply(x, y); !near(x, 5) || !near(y, -2) {
+			t.Errorf("%+v and back maps 5,-2 to %g,%g", transform, x, y)
+		}
+	}
+
+	singular := []Transform{
+		Identity().Scale(0, 1),
+		Identity().Translate(4, 4).Scale(2, 0),
+		{A: 1, B: 2, C: 2, D: 4},
+		{},
+	}
+	for _, transform := range singular {
+		if inverse, ok := transform.Invert(); ok || inverse != (Transform{}) {
+			t.Errorf("Invert of singular %+v = %+v %v", transform, inverse, ok)
+		}
+	}
+}
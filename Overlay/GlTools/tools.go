@@ -2,14 +2,10 @@ package GlTools
 
 import (
 	"DrawerGO/Overlay/Engine"
-	"DrawerGO/Overlay/Type"
 	"fmt"
 	"github.com/go-gl/gl/v4.6-core/gl"
 	"image"
 	"image/draw"
-	_ "image/jpeg"
-	_ "image/png"
-	"os"
 	"strings"
 )
 
@@ -17,80 +13,50 @@ type Attribute struct {
 	attribLocation uint32
 	offset, size   int32
 }
-type RenderObject struct {
-	vao, vbo, textureId uint32
-	dataLength          int32
-	mode                string
-}
-
-func (obj *RenderObject) Begin() {
-	BeginBuffers(obj.vao, obj.vbo, obj.textureId)
 
+// StreamBuffer is a vertex array whose buffer is refilled every frame.
+// Upload orphans the previous storage, so the driver never waits for draws
+// that still read it, and only grows the storage when the data outgrows it.
+type StreamBuffer struct {
+	vao, vbo uint32
+	capacity int
 }
-func (obj *RenderObject) Render() {
-	switch obj.mode {
-	case Type.Line:
-		{
-			gl.DrawArrays(gl.LINES, 0, obj.dataLength)
-		}
-	case Type.Rectangle:
-		{
-			gl.DrawArrays(gl.TRIANGLES, 0, obj.dataLength)
-		}
-	case Type.Text:
-		{
-			gl.DrawArrays(gl.TRIANGLES, 0, obj.dataLength)
-		}
-	case Type.Image:
-		{
-			gl.DrawArrays(gl.TRIANGLES, 0, obj.dataLength)
-		}
-	case Type.Polygon:
-		{
-			gl.DrawArrays(gl.TRIANGLES, 0, obj.dataLength)
-		}
-	case Type.Circle:
-		{
-			gl.DrawArrays(gl.TRIANGLE_FAN, 0, obj.dataLength)
-		}
-	case Type.OutlineRect:
-		{
-			gl.DrawArrays(gl.LINE_LOOP, 0, obj.dataLength)
-		}
+
+func NewStreamBuffer() StreamBuffer {
+	vao, vbo := MakeBuffers()
+	return StreamBuffer{
+		vao: vao,
+		vbo: vbo,
 	}
 }
 
-func (obj *RenderObject) End() {
-	EndBuffers()
+// Upload replaces the buffer content with Size bytes from Data, a pointer or
+// a non empty slice.
+func (buf *StreamBuffer) Upload(Data interface{}, Size int) {
+	gl.BindBuffer(gl.ARRAY_BUFFER, buf.vbo)
+	if Size > buf.capacity {
+		buf.capacity = Size * 2
+	}
+	gl.BufferData(gl.ARRAY_BUFFER, buf.capacity, nil, gl.STREAM_DRAW)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, Size, gl.Ptr(Data))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 }
-func (obj *RenderObject) UploadMesh(data []float32) {
-	UploadToArrayBuffer(obj.vbo, data)
-	obj.dataLength = int32(len(data))
-	data = nil
+func (buf *StreamBuffer) Begin() {
+	gl.BindVertexArray(buf.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, buf.vbo)
 }
-
-func (obj *RenderObject) Delete() {
+func (buf *StreamBuffer) End() {
 	EndBuffers()
-	DeleteBuffers(obj.vao, obj.vbo, obj.textureId)
+}
+func (buf *StreamBuffer) Delete() {
+	EndBuffers()
+	DeleteBuffers(buf.vao, buf.vbo)
 }
 func (attrib *Attribute) Use() {
 	gl.EnableVertexAttribArray(attrib.attribLocation)
 	gl.VertexAttribPointerWithOffset(attrib.attribLocation, attrib.size, gl.FLOAT, false, int32(Engine.VertexSize)*int32(Engine.FloatSize), uintptr(attrib.offset*int32(Engine.FloatSize)))
 }
-func (obj *RenderObject) ChangeTexture(TextureId uint32) {
-	obj.textureId = TextureId
-}
 
-func NewRenderObject(Mode string) RenderObject {
-	vao, vbo, textureId := MakeBuffers(false)
-	return RenderObject{
-		vao:        vao,
-		vbo:        vbo,
-		dataLength: 0,
-		textureId:  textureId,
-		mode:       Mode,
-	}
-}
 func NewAttribute(AttributeLocation uint32, size, offset int32) Attribute {
 	return Attribute{
 		attribLocation: AttributeLocation,
@@ -99,6 +65,21 @@ func NewAttribute(AttributeLocation uint32, size, offset int32) Attribute {
 	}
 }
 
+// ShaderError is a shader that failed to compile or a program that failed
+// to link. Stage is "vertex", "fragment" or "link" and Log the info log of
+// the driver.
+type ShaderError struct {
+	Stage string
+	Log   string
+}
+
+func (err *ShaderError) Error() string {
+	if err.Stage == "link" {
+		return fmt.Sprintf("link program: %s", err.Log)
+	}
+	return fmt.Sprintf("compile %s shader: %s", err.Stage, err.Log)
+}
+
 func NewProgram(Vertex, Fragment uint32) (uint32, error) {
 	program := gl.CreateProgram()
 	gl.AttachShader(program, Vertex)
@@ -112,7 +93,8 @@ func NewProgram(Vertex, Fragment uint32) (uint32, error) {
 
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-		return 0, fmt.Errorf("link program error: %v", log)
+		gl.DeleteProgram(program)
+		return 0, &ShaderError{Stage: "link", Log: strings.TrimRight(log, "\x00")}
 	}
 
 	return program, nil
@@ -134,38 +116,36 @@ func CompileShader(SourceCode string, ShaderType uint32) (uint32, error) {
 
 		log := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
 
-		return 0, fmt.Errorf("failed to compile shader: %v", log)
+		stage := "vertex"
+		if ShaderType == gl.FRAGMENT_SHADER {
+			stage = "fragment"
+		}
+		return 0, &ShaderError{Stage: stage, Log: strings.TrimRight(log, "\x00")}
 	}
 
 	return shader, nil
 }
-func DeleteBuffers(vao, vbo, textureId uint32) {
+func DeleteBuffers(vao, vbo uint32) {
 	if vao != 0 {
 		gl.DeleteVertexArrays(1, &vao)
 	}
 	if vbo != 0 {
 		gl.DeleteBuffers(1, &vbo)
 	}
-	if textureId != 0 {
-		gl.DeleteTextures(1, &textureId)
-	}
 }
-func MakeBuffers(textureEnabled bool) (uint32, uint32, uint32) {
-	var vao, vbo, textureId uint32
+func MakeBuffers() (uint32, uint32) {
+	var vao, vbo uint32
 
 	gl.GenVertexArrays(1, &vao)
 	gl.BindVertexArray(vao)
 
 	gl.GenBuffers(1, &vbo)
-	if textureEnabled {
-		textureId = MakeTexture(true)
-	}
 
 	gl.BindVertexArray(0)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	return vao, vbo, textureId
+	return vao, vbo
 }
 
 func MakeTexture(linear bool) uint32 {
@@ -188,18 +168,6 @@ func MakeTexture(linear bool) uint32 {
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 	return textureId
 }
-func UploadTexture(textureId uint32, TexturePath string) (int, int, error) {
-	file, err := os.Open(TexturePath)
-	defer file.Close()
-	if err != nil {
-		return 0, 0, err
-	}
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return 0, 0, err
-	}
-	return UploadTextureFromImage(textureId, img)
-}
 func UploadTextureFromImage(textureId uint32, Img image.Image) (int, int, error) {
 	rgba := image.NewRGBA(Img.Bounds())
 	draw.Draw(rgba, rgba.Bounds(), Img, image.Point{}, draw.Src)
@@ -222,23 +190,8 @@ func UploadTextureFromImage(textureId uint32, Img image.Image) (int, int, error)
 	return size.X, size.Y, nil
 }
 
-func BeginBuffers(Vao, Vbo, TextureId uint32) {
-	gl.BindVertexArray(Vao)
-	gl.BindBuffer(gl.ARRAY_BUFFER, Vbo)
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, TextureId)
-
-}
-
 func EndBuffers() {
 	gl.BindVertexArray(0)
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 }
-
-func UploadToArrayBuffer(Vbo uint32, data []float32) {
-	gl.BindBuffer(gl.ARRAY_BUFFER, Vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(data)*Engine.FloatSize, gl.Ptr(data), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	data = nil
-}
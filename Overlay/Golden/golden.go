@@ -0,0 +1,165 @@
+// Package Golden renders App draw calls with the headless software renderer
+// and compares the frames against PNG golden files.
+//
+// Use it from the tests of a feature, with the golden files in the testdata
+// directory of their package:
+//
+//	func TestLayersGolden(t *testing.T) {
+//		Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+//			Name: "layers", Width: 64, Height: 64,
+//			Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+//				app.SetLayer("hud")
+//				app.DrawRect(8, 8, 48, 48)
+//			},
+//		})
+//	}
+//
+// Run the tests with -update to rewrite the golden files from the current
+// output. A failing comparison writes <name>.got.png and <name>.diff.png next
+// to the golden file.
+package Golden
+
+import (
+	"DrawerGO/Overlay"
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the rendered frames")
+
+// Options configures how Assert compares a frame with its golden file.
+type Options struct {
+	// Dir holds the golden files. Empty means the testdata directory of the
+	// package under test.
+	Dir string
+	// Tolerance is the largest per-channel difference still counted as equal.
+	Tolerance uint8
+	// Update rewrites the golden files with the rendered frames instead of
+	// comparing them, as the -update flag does.
+	Update bool
+}
+
+// Render draws one frame into a fresh Width x Height headless App and fails
+// t when the frame can not be rendered.
+func Render(t testing.TB, Width, Height int, Draw func(app *Overlay.App)) image.Image {
+	t.Helper()
+	app := Overlay.NewHeadless(Width, Height)
+	defer app.Dispose()
+	Draw(&app)
+	if err := app.Render(); err != nil {
+		t.Fatal(err)
+	}
+	return app.Frame()
+}
+
+// Assert compares Frame with the golden file Name.png, or rewrites the
+// golden file when Opts.Update is set.
+func Assert(t testing.TB, Name string, Frame image.Image, Opts Options) {
+	t.Helper()
+	dir := Opts.Dir
+	if dir == "" {
+		dir = "testdata"
+	}
+	path := filepath.Join(dir, Name+".png")
+
+	if Opts.Update || *update {
+		if err := SavePNG(path, Frame); err != nil {
+			t.Fatalf("golden %s: %v", Name, err)
+		}
+		return
+	}
+
+	want, err := LoadPNG(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v (set Options.Update to create it)", Name, err)
+	}
+	mismatched, diff := Compare(Frame, want, Opts.Tolerance)
+	if mismatched == 0 {
+		return
+	}
+	gotPath := filepath.Join(dir, Name+".got.png")
+	diffPath := filepath.Join(dir, Name+".diff.png")
+	if err := SavePNG(gotPath, Frame); err != nil {
+		t.Errorf("golden %s: %v", Name, err)
+	}
+	if err := SavePNG(diffPath, diff); err != nil {
+		t.Errorf("golden %s: %v", Name, err)
+	}
+	t.Errorf("golden %s: %d pixels differ by more than %d, see %s", Name, mismatched, Opts.Tolerance, diffPath)
+}
+
+// Compare counts the pixels of Got that differ from Want by more than
+// Tolerance in any channel. The returned diff image shows Want in gray with
+// the mismatching pixels in red. Images of different size mismatch entirely.
+func Compare(Got, Want image.Image, Tolerance uint8) (int, *image.RGBA) {
+	bounds := Want.Bounds()
+	diff := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	if Got.Bounds().Size() != bounds.Size() {
+		draw.Draw(diff, diff.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+		return bounds.Dx() * bounds.Dy(), diff
+	}
+
+	gotMin := Got.Bounds().Min
+	mismatched := 0
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			got := color.RGBAModel.Convert(Got.At(gotMin.X+x, gotMin.Y+y)).(color.RGBA)
+			want := color.RGBAModel.Convert(Want.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			if channelDiff(got.R, want.R) > Tolerance ||
+				channelDiff(got.G, want.G) > Tolerance ||
+				channelDiff(got.B, want.B) > Tolerance ||
+				channelDiff(got.A, want.A) > Tolerance {
+				mismatched++
+				diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+			gray := uint8((uint16(want.R) + uint16(want.G) + uint16(want.B)) / 3 / 4)
+			diff.SetRGBA(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+	return mismatched, diff
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func LoadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+func SavePNG(path string, Img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, Img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// TestdataDir returns the testdata directory next to this package's source.
+func TestdataDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata")
+}
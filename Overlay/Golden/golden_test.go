@@ -0,0 +1,7 @@
+package Golden
+
+import "testing"
+
+func TestPrimitives(t *testing.T) {
+	RunPrimitives(t, Options{Tolerance: 2})
+}
@@ -0,0 +1,141 @@
+package Golden
+
+import (
+	"DrawerGO/Overlay"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Assets are the files a Case may load while drawing.
+type Assets struct {
+	FontPath  string
+	ImagePath string
+	BMFontDir string
+}
+
+// Case is a named sequence of draw calls rendered into a fresh headless App.
+// Draw fails t when an asset does not load.
+type Case struct {
+	Name          string
+	Width, Height int
+	Draw          func(t testing.TB, app *Overlay.App, assets Assets)
+}
+
+// Primitives covers every primitive of App, including the progress bar in
+// each ProgressBarDirection. Features built on them keep their golden cases
+// with their own tests.
+var Primitives = []Case{
+	{"line", 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		app.SetColor(255, 255, 0, 255)
+		app.DrawLine(4, 60, 60, 4)
+		app.DrawLine(4, 32, 60, 32)
+	}},
+	{"rect", 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		app.SetColor(255, 0, 0, 255)
+		app.DrawRect(8, 8, 32, 24)
+		app.SetColor(0, 0, 255, 128)
+		app.AnchorPoint(0.5, 0.5)
+		app.DrawRect(40, 40, 32, 24)
+	}},
+	{"outline_rect", 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		app.SetColor(0, 255, 0, 255)
+		app.DrawOutlineRect(8, 8, 48, 32)
+	}},
+	{"circle", 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		app.SetColor(0, 255, 255, 255)
+		app.DrawCircle(8, 8, 48, 32)
+	}},
+	{"polygon", 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		app.SetColor(255, 0, 255, 255)
+		app.DrawPolygon(8, 56, 32, 8, 56, 56)
+	}},
+	{"image", 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		img, w, h, err := app.LoadImage(assets.ImagePath)
+		Must(t, err)
+		app.DrawImage(8, 8, float32(w)*3, float32(h)*3, img)
+	}},
+	{"text", 128, 48, func(t testing.TB, app *Overlay.App, assets Assets) {
+		font, err := app.LoadFont(assets.FontPath, 16)
+		Must(t, err)
+		app.SetColor(255, 255, 255, 255)
+		app.DrawText(4, 4, 16, 0, 0, font, 1, "Overlay\nGo 123")
+	}},
+	progressBarCase("progress_bar_left", Overlay.PROGRESS_BAR_DIRECTION_LEFT),
+	progressBarCase("progress_bar_right", Overlay.PROGRESS_BAR_DIRECTION_RIGHT),
+	progressBarCase("progress_bar_top", Overlay.PROGRESS_BAR_DIRECTION_TOP),
+	progressBarCase("progress_bar_bottom", Overlay.PROGRESS_BAR_DIRECTION_BOTTOM),
+	progressBarCase("progress_bar_center", Overlay.PROGRESS_BAR_DIRECTION_CENTER),
+}
+
+func progressBarCase(Name string, Direction Overlay.ProgressBarDirection) Case {
+	return Case{Name, 64, 64, func(t testing.TB, app *Overlay.App, assets Assets) {
+		app.SetColor(0, 255, 0, 255)
+		app.DrawProgressBar(8, 8, 48, 48, 30, 100, Direction)
+	}}
+}
+
+// RunPrimitives runs the cases of Primitives against the golden files of
+// this package.
+func RunPrimitives(t *testing.T, Opts Options) {
+	Run(t, Opts, Primitives...)
+}
+
+// Run renders every case as a subtest and asserts it against its golden
+// file.
+func Run(t *testing.T, Opts Options, Cases ...Case) {
+	assets := NewAssets(t)
+	for _, c := range Cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			frame := Render(t, c.Width, c.Height, func(app *Overlay.App) {
+				c.Draw(t, app, assets)
+			})
+			Assert(t, c.Name, frame, Opts)
+		})
+	}
+}
+
+// NewAssets writes the image of the cases to a temporary directory and
+// finds the font and BMFont files they share.
+func NewAssets(t testing.TB) Assets {
+	t.Helper()
+	assets := Assets{
+		FontPath:  filepath.Join(TestdataDir(), "..", "..", "..", "Fonts", "Vcr.ttf"),
+		ImagePath: filepath.Join(t.TempDir(), "checker.png"),
+		BMFontDir: filepath.Join(TestdataDir(), "bmfont"),
+	}
+	if _, err := os.Stat(assets.FontPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := SavePNG(assets.ImagePath, checker(8, 4)); err != nil {
+		t.Fatal(err)
+	}
+	return assets
+}
+
+// Must stops the case when loading one of its assets failed, so a broken
+// loader can not record a blank frame as the golden.
+func Must(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checker returns a Size x Size image of Cell sized red and white squares.
+func checker(Size, Cell int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, Size, Size))
+	for y := 0; y < Size; y++ {
+		for x := 0; x < Size; x++ {
+			if (x/Cell+y/Cell)%2 == 0 {
+				img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
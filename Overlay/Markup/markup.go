@@ -0,0 +1,203 @@
+// Package Markup parses the small tag language of App.DrawRichText into
+// styled runs of text.
+//
+//	[color=#ff0000]crit[/color]  text color, #rgb, #rrggbb or #rrggbbaa
+//	[b]bold[/b]                   bold
+//	[i]italic[/i]                 italic
+//	[u]underline[/u]              underline
+//	[s]strike[/s]                 strikethrough
+//	[font=mono]code[/font]        a font registered under that name
+//	[size=24]big[/size]           size in pixels
+//
+// Tags nest, and a closing tag ends the innermost open tag of its kind. "[["
+// is a literal "[", and anything in brackets that is not a known tag is kept
+// as text.
+package Markup
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Run is text with one style. HasColor, Font and Size are unset, false, ""
+// and 0, when no tag around the run sets them.
+type Run struct {
+	Text                                   string
+	Color                                  [4]float32
+	HasColor                               bool
+	Font                                   string
+	Size                                   float32
+	Bold, Italic, Underline, Strikethrough bool
+}
+
+type style struct {
+	colors                                 []([4]float32)
+	fonts                                  []string
+	sizes                                  []float32
+	bold, italic, underline, strikethrough int
+}
+
+func (s *style) run(Text string) Run {
+	run := Run{
+		Text:          Text,
+		Bold:          s.bold > 0,
+		Italic:        s.italic > 0,
+		Underline:     s.underline > 0,
+		Strikethrough: s.strikethrough > 0,
+	}
+	if n := len(s.colors); n > 0 {
+		run.Color = s.colors[n-1]
+		run.HasColor = true
+	}
+	if n := len(s.fonts); n > 0 {
+		run.Font = s.fonts[n-1]
+	}
+	if n := len(s.sizes); n > 0 {
+		run.Size = s.sizes[n-1]
+	}
+	return run
+}
+
+// apply changes the style by Tag, the text between the brackets, and
+// reports whether it is a known tag. Unknown tags leave the style alone.
+func (s *style) apply(Tag string) bool {
+	name, value, hasValue := strings.Cut(Tag, "=")
+	closing := strings.HasPrefix(name, "/")
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	if closing && hasValue {
+		return false
+	}
+	switch name {
+	case "b":
+		return s.toggle(&s.bold, closing, hasValue)
+	case "i":
+		return s.toggle(&s.italic, closing, hasValue)
+	case "u":
+		return s.toggle(&s.underline, closing, hasValue)
+	case "s":
+		return s.toggle(&s.strikethrough, closing, hasValue)
+	case "color":
+		if closing {
+			if n := len(s.colors); n > 0 {
+				s.colors = s.colors[:n-1]
+			}
+			return true
+		}
+		color, ok := ParseColor(value)
+		if ok {
+			s.colors = append(s.colors, color)
+		}
+		return ok
+	case "font":
+		if closing {
+			if n := len(s.fonts); n > 0 {
+				s.fonts = s.fonts[:n-1]
+			}
+			return true
+		}
+		if value == "" {
+			return false
+		}
+		s.fonts = append(s.fonts, value)
+		return true
+	case "size":
+		if closing {
+			if n := len(s.sizes); n > 0 {
+				s.sizes = s.sizes[:n-1]
+			}
+			return true
+		}
+		size, err := strconv.ParseFloat(value, 32)
+		if err != nil || size <= 0 {
+			return false
+		}
+		s.sizes = append(s.sizes, float32(size))
+		return true
+	}
+	return false
+}
+
+func (s *style) toggle(Count *int, Closing, HasValue bool) bool {
+	if HasValue {
+		return false
+	}
+	if !Closing {
+		*Count++
+	} else if *Count > 0 {
+		*Count--
+	}
+	return true
+}
+
+// Parse splits Text into runs, dropping the tags. Neighbouring runs always
+// differ in style; empty runs are left out.
+func Parse(Text string) []Run {
+	var runs []Run
+	var s style
+	var text strings.Builder
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		run := s.run(text.String())
+		text.Reset()
+		if n := len(runs); n > 0 && sameStyle(runs[n-1], run) {
+			runs[n-1].Text += run.Text
+			return
+		}
+		runs = append(runs, run)
+	}
+	for i := 0; i < len(Text); {
+		if Text[i] != '[' {
+			text.WriteByte(Text[i])
+			i++
+			continue
+		}
+		if strings.HasPrefix(Text[i:], "[[") {
+			text.WriteByte('[')
+			i += 2
+			continue
+		}
+		end := strings.IndexByte(Text[i:], ']')
+		if end < 0 {
+			text.WriteString(Text[i:])
+			break
+		}
+		flush()
+		if !s.apply(Text[i+1 : i+end]) {
+			text.WriteString(Text[i : i+end+1])
+		}
+		i += end + 1
+	}
+	flush()
+	return runs
+}
+
+func sameStyle(a, b Run) bool {
+	a.Text, b.Text = "", ""
+	return a == b
+}
+
+// ParseColor reads #rgb, #rrggbb or #rrggbbaa.
+func ParseColor(Value string) ([4]float32, bool) {
+	hex := strings.TrimPrefix(Value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 || !strings.HasPrefix(Value, "#") {
+		return [4]float32{}, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [4]float32{}, false
+	}
+	return [4]float32{
+		float32(value>>24&0xff) / 255,
+		float32(value>>16&0xff) / 255,
+		float32(value>>8&0xff) / 255,
+		float32(value&0xff) / 255,
+	}, true
+}
@@ -0,0 +1,82 @@
+package Markup
+
+import (
+	"reflect"
+	"testing"
+)
+
+var red = [4]float32{1, 0, 0, 1}
+var green = [4]float32{0, 1, 0, 1}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Run
+	}{
+		{"plain", "hit", []Run{{Text: "hit"}}},
+		{"empty", "", nil},
+		{"styles", "a[b]b[/b][i]i[/i][u]u[/u][s]s[/s]", []Run{
+			{Text: "a"}, {Text: "b", Bold: true}, {Text: "i", Italic: true},
+			{Text: "u", Underline: true}, {Text: "s", Strikethrough: true},
+		}},
+		{"nesting", "[b]a[i]b[/i]c[/b]", []Run{
+			{Text: "a", Bold: true}, {Text: "b", Bold: true, Italic: true}, {Text: "c", Bold: true},
+		}},
+		{"innermost color", "[color=#f00]a[color=#0f0]b[/color]c[/color]d", []Run{
+			{Text: "a", Color: red, HasColor: true}, {Text: "b", Color: green, HasColor: true},
+			{Text: "c", Color: red, HasColor: true}, {Text: "d"},
+		}},
+		{"innermost size and font", "[font=mono][size=24][font=big]a[/font]b[/size]c[/font]", []Run{
+			{Text: "a", Font: "big", Size: 24}, {Text: "b", Font: "mono", Size: 24}, {Text: "c", Font: "mono"},
+		}},
+		{"repeated bold", "[b][b]a[/b]b[/b]c", []Run{{Text: "ab", Bold: true}, {Text: "c"}}},
+		{"unopened close", "a[/b][/color]b", []Run{{Text: "ab"}}},
+		{"upper case", "[B]a[/B]", []Run{{Text: "a", Bold: true}}},
+		{"escape", "[[b]a[[[b]c", []Run{{Text: "[b]a["}, {Text: "c", Bold: true}}},
+		{"unknown tags", "[x]a[/x] [b=1]b [color=red]c [size=-1]d [font=]e [/b=1]", []Run{
+			{Text: "[x]a[/x] [b=1]b [color=red]c [size=-1]d [font=]e [/b=1]"},
+		}},
+		{"unclosed bracket", "a[b", []Run{{Text: "a[b"}}},
+		{"merged runs", "a[b][/b]b[color=#f00][/color]c[i]d[/i][i]e[/i]", []Run{
+			{Text: "abc"}, {Text: "de", Italic: true},
+		}},
+		{"same style after close", "[b]a[/b][b]b[/b]", []Run{{Text: "ab", Bold: true}}},
+	}
+	for _, test := range tests {
+		if got := Parse(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Parse(%q) = %+v, want %+v", test.name, test.text, got, test.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  [4]float32
+		ok    bool
+	}{
+		{"#f00", red, true},
+		{"#0F0", green, true},
+		{"#ff0000", red, true},
+		{"#00ff00", green, true},
+		{"#ff000000", [4]float32{1, 0, 0, 0}, true},
+		{"#33669980", [4]float32{0.2, 0.4, 0.6, 128.0 / 255}, true},
+		{"", [4]float32{}, false},
+		{"#", [4]float32{}, false},
+		{"f00", [4]float32{}, false},
+		{"ff0000", [4]float32{}, false},
+		{"#ff00", [4]float32{}, false},
+		{"#ff00000", [4]float32{}, false},
+		{"#ff0000000", [4]float32{}, false},
+		{"#ggg", [4]float32{}, false},
+		{"#+f0000ff", [4]float32{}, false},
+		{"red", [4]float32{}, false},
+	}
+	for _, test := range tests {
+		got, ok := ParseColor(test.value)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ParseColor(%q) = %v %v, want %v %v", test.value, got, ok, test.want, test.ok)
+		}
+	}
+}
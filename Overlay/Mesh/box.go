@@ -0,0 +1,229 @@
+package Mesh
+
+import (
+	"DrawerGO/Overlay/Shape"
+	"DrawerGO/Overlay/ttf2atlas"
+	"strings"
+)
+
+// Box is the rectangle Box lays text out in, in pixels at the font size,
+// with its top left at 0, 0. A Width of 0 does not wrap and a Height of 0
+// does not truncate or align vertically.
+//
+// AlignX and AlignY place the lines in the box: 0 at the left or top, 0.5 in
+// the middle and 1 at the right or bottom. Justify stretches the spaces of
+// every wrapped line so it fills the width; the last line of a paragraph
+// keeps AlignX. LineSpacing multiplies the distance between lines, 1 when 0.
+// With Ellipsis set, lines below the box are dropped and the last line that
+// fits ends in an ellipsis. TabSize is the distance between tab stops in
+// spaces, see Layout.
+type Box struct {
+	Width, Height  float32
+	AlignX, AlignY float32
+	Justify        bool
+	LineSpacing    float32
+	Ellipsis       bool
+	TabSize        int
+}
+
+// Layout wraps str at spaces and tabs so every line fits Box.Width,
+// breaking inside words only when a word alone is wider, and aligns the
+// lines. Spaces at the end of a wrapped line hang outside of it. Otherwise it
+// lays text out the way Layout does.
+func (box Box) Layout(str string, Font ttf2atlas.Font, Interval float32, Kerning bool) TextLayout {
+	metrics := Font.FontMetrics()
+	spacing := box.LineSpacing
+	if spacing == 0 {
+		spacing = 1
+	}
+	layout := TextLayout{LineHeight: metrics.LineHeight()}
+	pitch := layout.LineHeight * spacing
+
+	// last marks the lines that end a paragraph, which justify leaves alone.
+	var last []bool
+	start := 0
+	for _, paragraph := range strings.Split(str, "\n") {
+		spans := box.wrap(paragraph, Font, Interval, Kerning)
+		for i, span := range spans {
+			layout.Lines = append(layout.Lines, layoutLine(paragraph[span[0]:span[1]], start+span[0], Font, Interval, Kerning, box.TabSize))
+			last = append(last, i == len(spans)-1)
+		}
+		start += len(paragraph) + 1
+	}
+
+	if box.Ellipsis && box.Height > 0 {
+		fit := 1
+		if box.Height > layout.LineHeight {
+			fit += int((box.Height - layout.LineHeight) / pitch)
+		}
+		if fit < len(layout.Lines) {
+			layout.Lines = layout.Lines[:fit]
+			last = last[:fit]
+			last[fit-1] = true
+			layout.Lines[fit-1] = box.ellipsis(layout.Lines[fit-1], Font, Interval, Kerning)
+		}
+	}
+
+	for i := range layout.Lines {
+		line := &layout.Lines[i]
+		if box.Justify && box.Width > 0 && !last[i] {
+			justify(line, box.Width)
+		}
+		line.X = (box.Width - line.Width) * box.AlignX
+		if box.Width == 0 {
+			line.X = -line.Width * box.AlignX
+		}
+		for j := range line.Glyphs {
+			line.Glyphs[j].X += line.X
+		}
+		line.Y = float32(i) * pitch
+		if line.Width > layout.Width {
+			layout.Width = line.Width
+		}
+	}
+	if n := len(layout.Lines); n > 0 {
+		layout.Height = float32(n-1)*pitch + layout.LineHeight
+	}
+	if box.Height > 0 {
+		offset := (box.Height - layout.Height) * box.AlignY
+		for i := range layout.Lines {
+			line := &layout.Lines[i]
+			line.Y += offset
+		}
+	}
+	return layout
+}
+
+// wrap splits a paragraph into the byte ranges of its lines.
+func (box Box) wrap(Paragraph string, Font ttf2atlas.Font, Interval float32, Kerning bool) [][2]int {
+	if box.Width <= 0 {
+		return [][2]int{{0, len(Paragraph)}}
+	}
+	var spans [][2]int
+	lineStart := 0
+	// wordEnd is where the line ends when it breaks at the last space seen,
+	// next where the line after it starts; next is -1 without such a space.
+	wordEnd, next := 0, -1
+	space := false
+	// word is the width of the clusters since the last space laid out from
+	// the start of a line, where they go when the line breaks before them.
+	var pen, word float32
+	var previous rune = -1
+	for _, cluster := range Shape.Clusters(Paragraph) {
+		i := cluster.Index
+		if first := cluster.Runes[0]; first == ' ' || first == '\t' {
+			if !space {
+				wordEnd = i
+			}
+			space = true
+			next = i + cluster.Size
+			word = 0
+			if first == '\t' {
+				pen += tabAdvance(pen, Font, Interval, box.TabSize)
+				previous = -1
+			} else if glyph, ok := Font.Glyph(' '); ok {
+				pen += glyph.Advance * Interval
+				previous = ' '
+			}
+			continue
+		}
+		char, _ := cluster.Resolve(hasGlyph(Font))
+		glyph, ok := Font.Glyph(char)
+		if !ok {
+			continue
+		}
+		// first is set for the first cluster of a word, which carries no
+		// kerning into the word.
+		first := space
+		space = false
+		advance := glyph.Advance * Interval
+		var kern float32
+		if Kerning && previous >= 0 {
+			kern = Font.Kern(previous, char)
+		}
+		if pen+kern+advance > box.Width && i > lineStart {
+			if next > lineStart {
+				spans = append(spans, [2]int{lineStart, wordEnd})
+				lineStart = next
+				pen = word
+			} else {
+				spans = append(spans, [2]int{lineStart, i})
+				lineStart = i
+				pen = 0
+				first = true
+			}
+			next = -1
+			if first {
+				previous = -1
+				kern = 0
+			}
+		}
+		pen += kern
+		pen += advance
+		if first {
+			word = advance
+		} else {
+			word += kern
+			word += advance
+		}
+		previous = char
+	}
+	return append(spans, [2]int{lineStart, len(strings.TrimRight(Paragraph, " \t"))})
+}
+
+// ellipsis drops clusters from the end of Line until an ellipsis fits after
+// them within the width of the box, and appends it. It uses U+2026 when the
+// font has it and three dots otherwise.
+func (box Box) ellipsis(Line LineLayout, Font ttf2atlas.Font, Interval float32, Kerning bool) LineLayout {
+	dots := "…"
+	if _, ok := Font.Glyph('…'); !ok {
+		dots = "..."
+	}
+	suffix := layoutLine(dots, Line.End, Font, Interval, Kerning, box.TabSize)
+	trailing := func() bool {
+		n := len(Line.Glyphs)
+		return n > 0 && (Line.Glyphs[n-1].Rune == ' ' || Line.Glyphs[n-1].Rune == '\t')
+	}
+	for trailing() || (box.Width > 0 && Line.Width+suffix.Width > box.Width && len(Line.Glyphs) > 0) {
+		// Drop the last cluster with the marks on it.
+		n := len(Line.Glyphs)
+		index := Line.Glyphs[n-1].Index
+		for n > 0 && Line.Glyphs[n-1].Index == index {
+			n--
+		}
+		Line.Width = Line.Glyphs[n].X
+		Line.Glyphs = Line.Glyphs[:n]
+	}
+	for _, glyph := range suffix.Glyphs {
+		glyph.Index = Line.End
+		glyph.Size = 0
+		glyph.X += Line.Width
+		Line.Glyphs = append(Line.Glyphs, glyph)
+	}
+	Line.Width += suffix.Width
+	return Line
+}
+
+// justify widens the spaces between the words of Line so it is Width wide.
+func justify(Line *LineLayout, Width float32) {
+	spaces := 0
+	for _, glyph := range Line.Glyphs {
+		if glyph.Rune == ' ' {
+			spaces++
+		}
+	}
+	if spaces == 0 || Line.Width >= Width {
+		return
+	}
+	extra := (Width - Line.Width) / float32(spaces)
+	var shift float32
+	for i := range Line.Glyphs {
+		glyph := &Line.Glyphs[i]
+		glyph.X += shift
+		if glyph.Rune == ' ' {
+			glyph.Advance += extra
+			shift += extra
+		}
+	}
+	Line.Width = Width
+}
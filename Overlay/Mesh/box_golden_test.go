@@ -0,0 +1,44 @@
+package Mesh_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestTextBoxGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "text_box", Width: 160, Height: 160,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			font, err := app.LoadFont(assets.FontPath, 16)
+			Golden.Must(t, err)
+			text := "The quick brown fox jumps over the lazy dog"
+			boxes := []struct {
+				x, y       float32
+				horizontal Overlay.TextAlign
+				vertical   Overlay.TextVerticalAlign
+			}{
+				{4, 4, Overlay.TEXT_ALIGN_LEFT, Overlay.TEXT_ALIGN_TOP},
+				{82, 4, Overlay.TEXT_ALIGN_RIGHT, Overlay.TEXT_ALIGN_BOTTOM},
+				{4, 82, Overlay.TEXT_ALIGN_CENTER, Overlay.TEXT_ALIGN_MIDDLE},
+				{82, 82, Overlay.TEXT_ALIGN_JUSTIFY, Overlay.TEXT_ALIGN_TOP},
+			}
+			for i, box := range boxes {
+				app.SetColor(0, 0, 96, 255)
+				app.DrawRect(box.x, box.y, 74, 74)
+				app.SetColor(255, 255, 255, 255)
+				app.SetTextAlign(box.horizontal, box.vertical)
+				app.SetEllipsis(i == 1)
+				app.SetLineSpacing(1)
+				if i == 2 {
+					app.SetLineSpacing(0.8)
+				}
+				size := float32(10)
+				if i == 1 {
+					size = 16
+				}
+				app.DrawTextBox(box.x, box.y, 74, 74, size, font, text)
+			}
+		},
+	})
+}
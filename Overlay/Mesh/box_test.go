@@ -0,0 +1,125 @@
+package Mesh
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"reflect"
+	"testing"
+)
+
+// boxFont has letters 10 wide, a space 5 wide and a dot 2 wide, lines 10
+// apart and no U+2026.
+func boxFont() *ttf2atlas.BitmapFont {
+	return &ttf2atlas.BitmapFont{
+		Metrics: ttf2atlas.Metrics{FontSize: 10, Ascent: 8, Descent: 2},
+		Glyphs: map[rune]ttf2atlas.Glyph{
+			'a': {Width: 8, Height: 8, Advance: 10},
+			'b': {Width: 8, Height: 8, Advance: 10},
+			'c': {Width: 8, Height: 8, Advance: 10},
+			' ': {Advance: 5},
+			'.': {Width: 2, Height: 2, Advance: 2},
+		},
+	}
+}
+
+// line is what a test checks of a LineLayout.
+type line struct {
+	text        string
+	x, y, width float32
+}
+
+func lines(Layout TextLayout) []line {
+	var out []line
+	for _, l := range Layout.Lines {
+		var runes []rune
+		for _, glyph := range l.Glyphs {
+			runes = append(runes, glyph.Rune)
+		}
+		out = append(out, line{string(runes), l.X, l.Y, l.Width})
+	}
+	return out
+}
+
+func TestBoxWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		box   Box
+		want  []line
+		width float32
+	}{
+		{"no width", "aa bb cc", Box{}, []line{{"aa bb cc", 0, 0, 70}}, 70},
+		{"at spaces", "aa bb cc", Box{Width: 50}, []line{{"aa bb", 0, 0, 45}, {"cc", 0, 10, 20}}, 45},
+		{"hanging spaces", "aa bb   cc", Box{Width: 50}, []line{{"aa bb", 0, 0, 45}, {"cc", 0, 10, 20}}, 45},
+		{"inside a long word", "aaaaaa b", Box{Width: 35}, []line{{"aaa", 0, 0, 30}, {"aaa", 0, 10, 30}, {"b", 0, 20, 10}}, 30},
+		{"paragraphs", "aa\nbb cc", Box{Width: 100}, []line{{"aa", 0, 0, 20}, {"bb cc", 0, 10, 45}}, 45},
+		{"right", "aa bb cc", Box{Width: 50, AlignX: 1}, []line{{"aa bb", 5, 0, 45}, {"cc", 30, 10, 20}}, 45},
+		{"center", "aa bb cc", Box{Width: 50, AlignX: 0.5}, []line{{"aa bb", 2.5, 0, 45}, {"cc", 15, 10, 20}}, 45},
+		{"center without width", "aa", Box{AlignX: 0.5}, []line{{"aa", -10, 0, 20}}, 20},
+		{"line spacing", "aa bb cc", Box{Width: 50, LineSpacing: 2}, []line{{"aa bb", 0, 0, 45}, {"cc", 0, 20, 20}}, 45},
+		{"bottom", "aa bb cc", Box{Width: 50, Height: 40, AlignY: 1}, []line{{"aa bb", 0, 20, 45}, {"cc", 0, 30, 20}}, 45},
+		{"middle", "aa bb cc", Box{Width: 50, Height: 40, AlignY: 0.5}, []line{{"aa bb", 0, 10, 45}, {"cc", 0, 20, 20}}, 45},
+	}
+	for _, test := range tests {
+		layout := test.box.Layout(test.text, boxFont(), 1, false)
+		if got := lines(layout); !reflect.DeepEqual(got, test.want) || layout.Width != test.width {
+			t.Errorf("%s: lines %+v width %g, want %+v width %g", test.name, got, layout.Width, test.want, test.width)
+		}
+	}
+}
+
+func TestBoxWrapKerning(t *testing.T) {
+	font := boxFont()
+	font.Kerning = map[ttf2atlas.KerningPair]float32{{Left: 'a', Right: 'b'}: -5, {Left: ' ', Right: 'b'}: -2}
+	// A word moved to the next line keeps the kerning inside it and drops
+	// the one with the space before it.
+	layout := Box{Width: 25}.Layout("bab bab", font, 1, true)
+	want := []line{{"bab", 0, 0, 25}, {"bab", 0, 10, 25}}
+	if got := lines(layout); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines %+v, want %+v", got, want)
+	}
+}
+
+func TestBoxJustify(t *testing.T) {
+	layout := Box{Width: 50, Justify: true, AlignX: 1}.Layout("aa bb cc\naa c", boxFont(), 1, false)
+	// The wrapped line is stretched, the last lines of both paragraphs
+	// keep the alignment.
+	want := []line{{"aa bb", 0, 0, 50}, {"cc", 30, 10, 20}, {"aa c", 15, 20, 35}}
+	if got := lines(layout); !reflect.DeepEqual(got, want) {
+		t.Fatalf("lines %+v, want %+v", got, want)
+	}
+	var xs, advances []float32
+	for _, glyph := range layout.Lines[0].Glyphs {
+		xs = append(xs, glyph.X)
+		advances = append(advances, glyph.Advance)
+	}
+	if want := []float32{0, 10, 20, 30, 40}; !reflect.DeepEqual(xs, want) {
+		t.Errorf("justified glyphs at %v, want %v", xs, want)
+	}
+	if want := []float32{10, 10, 10, 10, 10}; !reflect.DeepEqual(advances, want) {
+		t.Errorf("justified advances %v, want %v", advances, want)
+	}
+}
+
+func TestBoxEllipsis(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		box  Box
+		want []line
+	}{
+		// "aa bb..." is 51 wide, so a letter goes.
+		{"one line", "aa bb cc", Box{Width: 50, Height: 10, Ellipsis: true}, []line{{"aa b...", 0, 0, 41}}},
+		// The space before a dropped word does not stay in front of the dots.
+		{"at a space", "aa b cc", Box{Width: 38, Height: 10, Ellipsis: true}, []line{{"aa...", 0, 0, 26}}},
+		{"two lines", "aa bb cc aa bb", Box{Width: 50, Height: 25, Ellipsis: true}, []line{{"aa bb", 0, 0, 45}, {"cc a...", 0, 10, 41}}},
+		{"everything fits", "aa bb cc", Box{Width: 50, Height: 20, Ellipsis: true}, []line{{"aa bb", 0, 0, 45}, {"cc", 0, 10, 20}}},
+		{"without ellipsis", "aa bb cc", Box{Width: 50, Height: 10}, []line{{"aa bb", 0, 0, 45}, {"cc", 0, 10, 20}}},
+		{"lower than a line", "aa bb cc", Box{Width: 50, Height: 4, Ellipsis: true}, []line{{"aa b...", 0, 0, 41}}},
+	}
+	for _, test := range tests {
+		layout := test.box.Layout(test.text, boxFont(), 1, false)
+		if got := lines(layout); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: lines %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
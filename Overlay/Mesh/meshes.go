@@ -1,9 +1,6 @@
 package Mesh
 
-import (
-	"math"
-	"strings"
-)
+import "math"
 
 /*
 X,Y, U,V
@@ -53,32 +50,3 @@ func Polygon(
 		X3, Y3, 0, 0,
 	}
 }
-func Text(str string, atlasWidth, atlasHeight int, fontSize, Interval float32) []float32 {
-	var data []float32
-	newLines := strings.Split(strings.ReplaceAll(str, "\t", " "), "\n")
-
-	xSymbols := int32(float32(atlasWidth) / fontSize)
-	normalFontSizeX := fontSize / float32(atlasWidth)
-	normalFontSizeY := fontSize / float32(atlasHeight)
-
-	for lineId, line := range newLines {
-		y := float32(lineId)
-		runes := []rune(line)
-		for charId, char := range runes {
-			texX := (float32(char%xSymbols) * fontSize) / float32(atlasWidth)
-			texY := (float32(char/xSymbols) * fontSize) / float32(atlasHeight)
-			x := float32(charId) * Interval
-			data = append(data,
-				x, y, texX, texY,
-				1.0+x, y, normalFontSizeX+texX, texY,
-				1.0+x, 1.0+y, normalFontSizeX+texX, normalFontSizeY+texY,
-
-				1.0+x, 1.0+y, normalFontSizeX+texX, normalFontSizeY+texY,
-				x, 1.0+y, texX, normalFontSizeY+texY,
-				x, y, texX, texY,
-			)
-		}
-	}
-
-	return data
-}
@@ -0,0 +1,221 @@
+package Mesh
+
+import (
+	"DrawerGO/Overlay/Shape"
+	"DrawerGO/Overlay/ttf2atlas"
+	"math"
+	"strings"
+)
+
+// DefaultTabSize is the distance between tab stops in spaces.
+const DefaultTabSize = 4
+
+// TextLayout is where Layout puts every glyph of a string, in pixels at the
+// font size with the top of the first line at 0.
+type TextLayout struct {
+	Width, Height float32
+	LineHeight    float32
+	Lines         []LineLayout
+}
+
+// LineLayout is one line of a TextLayout. Start and End are the byte
+// offsets of the line in the string, X and Y the top left of the line and
+// Width the distance from X to the pen position after its last glyph.
+type LineLayout struct {
+	Start, End  int
+	X, Y, Width float32
+	Glyphs      []GlyphLayout
+}
+
+// GlyphLayout is a glyph placed on a line. Index and Size are the bytes of
+// the grapheme cluster the glyph draws in the string; Rune is the rune drawn,
+// the precomposed one when marks were composed into it. X is where the pen
+// is when the glyph is drawn and Advance how far it moves after it,
+// Interval included. Marks drawn over a glyph follow it with an Advance
+// of 0, and a tab is a glyph without an image that advances to the next
+// tab stop.
+type GlyphLayout struct {
+	Rune        rune
+	Index, Size int
+	X           float32
+	Advance     float32
+	Glyph       ttf2atlas.Glyph
+}
+
+// Layout lays str out with the glyph metrics of Font. Lines split on "\n",
+// and tabs advance to the next multiple of TabSize spaces. Text is laid out
+// in grapheme clusters: marks compose with the letter before them when the
+// font has the precomposed glyph and are drawn over it otherwise. Every
+// cluster advances the pen by the advance width of its glyph times
+// Interval, adjusted by the kerning with the glyph before it when Kerning is
+// set; clusters Font cannot draw are skipped.
+func Layout(str string, Font ttf2atlas.Font, Interval float32, Kerning bool, TabSize int) TextLayout {
+	layout := TextLayout{LineHeight: Font.FontMetrics().LineHeight()}
+	start := 0
+	for lineId, line := range strings.Split(str, "\n") {
+		out := layoutLine(line, start, Font, Interval, Kerning, TabSize)
+		out.Y = float32(lineId) * layout.LineHeight
+		if out.Width > layout.Width {
+			layout.Width = out.Width
+		}
+		layout.Lines = append(layout.Lines, out)
+		start += len(line) + 1
+	}
+	layout.Height = float32(len(layout.Lines)) * layout.LineHeight
+	return layout
+}
+
+// LayoutAfter lays out str as the rest of a line, like Layout does a line
+// of its own, for text drawn in pieces such as the runs of rich text. Pen is
+// where the line is when str starts, in pixels of Font, and Previous the rune
+// before it, -1 when there is none or it was in another font: tabs advance to
+// the stops of the whole line and the first glyph is kerned against Previous.
+// The glyphs are placed from X 0 at Pen.
+func LayoutAfter(str string, Pen float32, Previous rune, Font ttf2atlas.Font, Interval float32, Kerning bool, TabSize int) TextLayout {
+	line := layoutSpan(str, 0, Pen, Previous, Font, Interval, Kerning, TabSize)
+	layout := TextLayout{Width: line.Width, LineHeight: Font.FontMetrics().LineHeight(), Lines: []LineLayout{line}}
+	layout.Height = layout.LineHeight
+	return layout
+}
+
+// LastRune is the rune the glyph after Line is kerned against, -1 when the
+// line is empty or ends in a tab.
+func LastRune(Line LineLayout) rune {
+	n := len(Line.Glyphs)
+	if n == 0 {
+		return -1
+	}
+	// Marks follow the base of their cluster with the same Index.
+	index := Line.Glyphs[n-1].Index
+	for n > 1 && Line.Glyphs[n-2].Index == index {
+		n--
+	}
+	if r := Line.Glyphs[n-1].Rune; r != '\t' {
+		return r
+	}
+	return -1
+}
+
+// layoutLine places the glyphs of one line from X 0. Start is the byte
+// offset of line in the whole string.
+func layoutLine(line string, Start int, Font ttf2atlas.Font, Interval float32, Kerning bool, TabSize int) LineLayout {
+	return layoutSpan(line, Start, 0, -1, Font, Interval, Kerning, TabSize)
+}
+
+// layoutSpan is layoutLine for a line that has already advanced to Pen
+// after the rune Previous. X and Width are measured from Pen.
+func layoutSpan(line string, Start int, Pen float32, Previous rune, Font ttf2atlas.Font, Interval float32, Kerning bool, TabSize int) LineLayout {
+	out := LineLayout{Start: Start, End: Start + len(line)}
+	pen := Pen
+	previous := Previous
+	for _, cluster := range Shape.Clusters(line) {
+		index := Start + cluster.Index
+		if cluster.Runes[0] == '\t' {
+			advance := tabAdvance(pen, Font, Interval, TabSize)
+			out.Glyphs = append(out.Glyphs, GlyphLayout{Rune: '\t', Index: index, Size: cluster.Size, X: pen - Pen, Advance: advance})
+			pen += advance
+			previous = -1
+			continue
+		}
+		base, marks := cluster.Resolve(hasGlyph(Font))
+		glyph, ok := Font.Glyph(base)
+		if !ok {
+			continue
+		}
+		if Kerning && previous >= 0 {
+			pen += Font.Kern(previous, base)
+		}
+		previous = base
+		advance := glyph.Advance * Interval
+		out.Glyphs = append(out.Glyphs, GlyphLayout{Rune: base, Index: index, Size: cluster.Size, X: pen - Pen, Advance: advance, Glyph: glyph})
+		for _, mark := range marks {
+			markGlyph, ok := Font.Glyph(mark)
+			if !ok {
+				continue
+			}
+			// Marks without an advance are designed to be drawn from the pen
+			// after the letter and reach back over it; others are centered.
+			x := pen - Pen + advance
+			if markGlyph.Advance > 0 {
+				x = pen - Pen + (advance-markGlyph.Advance)/2
+			}
+			out.Glyphs = append(out.Glyphs, GlyphLayout{Rune: mark, Index: index, Size: cluster.Size, X: x, Glyph: markGlyph})
+		}
+		pen += advance
+	}
+	out.Width = pen - Pen
+	return out
+}
+
+func hasGlyph(Font ttf2atlas.Font) func(rune) bool {
+	return func(r rune) bool {
+		_, ok := Font.Glyph(r)
+		return ok
+	}
+}
+
+// tabAdvance is how far a tab moves the pen from Pen to the next tab stop.
+// Tab stops are TabSize spaces apart, DefaultTabSize when it is not positive.
+func tabAdvance(Pen float32, Font ttf2atlas.Font, Interval float32, TabSize int) float32 {
+	if TabSize <= 0 {
+		TabSize = DefaultTabSize
+	}
+	space := Font.FontMetrics().FontSize / 2
+	if glyph, ok := Font.Glyph(' '); ok {
+		space = glyph.Advance
+	}
+	stop := space * Interval * float32(TabSize)
+	if stop <= 0 {
+		return 0
+	}
+	return float32(math.Floor(float64(Pen/stop))+1)*stop - Pen
+}
+
+// Text lays str out with Layout and returns two triangles per visible glyph,
+// per atlas page, indexed by Glyph.Page.
+func Text(str string, Font ttf2atlas.Font, Interval float32, Kerning bool, TabSize int) [][]float32 {
+	return Glyphs(Layout(str, Font, Interval, Kerning, TabSize), Font.FontMetrics().Ascent)
+}
+
+// Glyphs returns the quads of a layout, per atlas page. Ascent puts the
+// baseline of every line below its top.
+func Glyphs(Layout TextLayout, Ascent float32) [][]float32 {
+	var pages [][]float32
+	for _, line := range Layout.Lines {
+		baseline := line.Y + Ascent
+		for _, placed := range line.Glyphs {
+			glyph := placed.Glyph
+			if glyph.Width == 0 || glyph.Height == 0 {
+				continue
+			}
+			scale := glyph.Scale
+			if scale == 0 {
+				scale = 1
+			}
+			x := placed.X + glyph.BearingX
+			y := baseline - glyph.BearingY
+			x2 := x + float32(glyph.Width)*scale
+			y2 := y + float32(glyph.Height)*scale
+			for len(pages) <= glyph.Page {
+				pages = append(pages, nil)
+			}
+			pages[glyph.Page] = append(pages[glyph.Page],
+				x, y, glyph.U0, glyph.V0,
+				x2, y, glyph.U1, glyph.V0,
+				x2, y2, glyph.U1, glyph.V1,
+
+				x2, y2, glyph.U1, glyph.V1,
+				x, y2, glyph.U0, glyph.V1,
+				x, y, glyph.U0, glyph.V0,
+			)
+		}
+	}
+	return pages
+}
+
+// TextSize returns the size of the layout Text builds for the same
+// arguments: the widest line by the number of lines times the line height.
+func TextSize(str string, Font ttf2atlas.Font, Interval float32, Kerning bool, TabSize int) (float32, float32) {
+	layout := Layout(str, Font, Interval, Kerning, TabSize)
+	return layout.Width, layout.Height
+}
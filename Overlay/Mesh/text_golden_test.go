@@ -0,0 +1,32 @@
+package Mesh_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestUTF8TextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "utf8_text", Width: 160, Height: 112,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			font, err := app.LoadFont(assets.FontPath, 16)
+			Golden.Must(t, err)
+			// Cyrillic centered on a cross: anchoring must measure runes, not bytes.
+			app.SetColor(255, 0, 0, 255)
+			app.DrawLine(80, 0, 80, 32)
+			app.DrawLine(0, 16, 160, 16)
+			app.SetColor(255, 255, 255, 255)
+			app.With(func() {
+				app.AnchorPoint(0.5, 0.5)
+				app.DrawText(80, 16, 16, 0, 0, font, 1, "Привет, мир")
+			})
+			// Combining marks compose into the precomposed glyphs of the font, so
+			// both lines are drawn the same.
+			app.DrawText(4, 36, 16, 0, 0, font, 1, "Cafe\u0301 nai\u0308ve")
+			app.DrawText(4, 52, 16, 0, 0, font, 1, "Café naïve")
+			app.SetTabSize(2)
+			app.DrawText(4, 76, 16, 0, 0, font, 1, "a\tbc\td\nabc\te")
+		},
+	})
+}
@@ -0,0 +1,154 @@
+package Mesh
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func loadVcr(t *testing.T) *ttf2atlas.FontAtlas {
+	t.Helper()
+	_, atlas, err := ttf2atlas.FontToAtlas(filepath.Join("..", "..", "Fonts", "Vcr.ttf"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return atlas
+}
+
+func TestKerning(t *testing.T) {
+	atlas := loadVcr(t)
+	atlas.Kerning = map[ttf2atlas.KerningPair]float32{{Left: 'A', Right: 'V'}: -3}
+
+	kerned, _ := TextSize("AV", atlas, 1, true, DefaultTabSize)
+	plain, _ := TextSize("AV", atlas, 1, false, DefaultTabSize)
+	if kerned >= plain {
+		t.Fatalf("TextSize with kerning = %g, without = %g", kerned, plain)
+	}
+	if plain-kerned != 3 {
+		t.Errorf("kerning moved AV by %g, want 3", plain-kerned)
+	}
+	if reversed, _ := TextSize("VA", atlas, 1, true, DefaultTabSize); reversed != plain {
+		t.Errorf("TextSize(VA) = %g, want %g", reversed, plain)
+	}
+}
+
+// positions returns the runes and pen positions of the glyphs on the first
+// line of a layout.
+func positions(Layout TextLayout) ([]rune, []float32) {
+	var runes []rune
+	var xs []float32
+	for _, glyph := range Layout.Lines[0].Glyphs {
+		runes = append(runes, glyph.Rune)
+		xs = append(xs, glyph.X)
+	}
+	return runes, xs
+}
+
+func TestCyrillic(t *testing.T) {
+	atlas := loadVcr(t)
+	layout := Layout("Привет", atlas, 1, false, DefaultTabSize)
+	runes, xs := positions(layout)
+	if string(runes) != "Привет" {
+		t.Fatalf("runes = %q", string(runes))
+	}
+	var pen float32
+	for i, r := range runes {
+		if xs[i] != pen {
+			t.Errorf("%c at %g, want %g", r, xs[i], pen)
+		}
+		pen += atlas.Glyphs[r].Advance
+	}
+	if layout.Width != pen {
+		t.Errorf("width = %g, want %g", layout.Width, pen)
+	}
+	if glyph := layout.Lines[0].Glyphs[2]; glyph.Index != 4 || glyph.Size != 2 {
+		t.Errorf("glyph 2 covers bytes %d+%d, want 4+2", glyph.Index, glyph.Size)
+	}
+}
+
+func TestComposedAndDecomposed(t *testing.T) {
+	atlas := loadVcr(t)
+	nfc := Layout("\u00e9t\u00e9", atlas, 1, false, DefaultTabSize)
+	nfd := Layout("e\u0301te\u0301", atlas, 1, false, DefaultTabSize)
+	for _, layout := range []TextLayout{nfc, nfd} {
+		if runes, _ := positions(layout); string(runes) != "été" {
+			t.Errorf("runes = %q, want the precomposed é", string(runes))
+		}
+	}
+	if nfc.Width != nfd.Width {
+		t.Errorf("width NFC = %g, NFD = %g", nfc.Width, nfd.Width)
+	}
+	if glyph := nfd.Lines[0].Glyphs[2]; glyph.Index != 4 || glyph.Size != 3 {
+		t.Errorf("last cluster covers bytes %d+%d, want 4+3", glyph.Index, glyph.Size)
+	}
+}
+
+// markFont has a letter, a mark without an advance, a centered mark with
+// one and a spacing mark, and no precomposed glyphs.
+func markFont() *ttf2atlas.BitmapFont {
+	return &ttf2atlas.BitmapFont{
+		Metrics: ttf2atlas.Metrics{FontSize: 10, Ascent: 8, Descent: 2},
+		Glyphs: map[rune]ttf2atlas.Glyph{
+			'e':    {Width: 6, Height: 6, Advance: 8},
+			0x0301: {Width: 3, Height: 2, BearingX: -5, Advance: 0},
+			0x0302: {Width: 4, Height: 2, Advance: 4},
+			0x093f: {Width: 2, Height: 8, Advance: 3},
+		},
+	}
+}
+
+func TestMarks(t *testing.T) {
+	font := markFont()
+	tests := []struct {
+		text     string
+		interval float32
+		runes    []rune
+		xs       []float32
+		width    float32
+	}{
+		{"ee\u0301", 1, []rune{'e', 'e', 0x301}, []float32{0, 8, 16}, 16},
+		{"ee\u0301", 2, []rune{'e', 'e', 0x301}, []float32{0, 16, 32}, 32},
+		{"e\u0302e", 1, []rune{'e', 0x302, 'e'}, []float32{0, 2, 8}, 16},
+		{"e\u0302e", 2, []rune{'e', 0x302, 'e'}, []float32{0, 6, 16}, 32},
+		// A spacing mark advances like a letter.
+		{"e\u093fe", 1, []rune{'e', 0x93f, 'e'}, []float32{0, 8, 11}, 19},
+	}
+	for _, test := range tests {
+		layout := Layout(test.text, font, test.interval, false, DefaultTabSize)
+		runes, xs := positions(layout)
+		if string(runes) != string(test.runes) || !reflect.DeepEqual(xs, test.xs) || layout.Width != test.width {
+			t.Errorf("Layout(%q, %g) = %U at %v width %g, want %U at %v width %g",
+				test.text, test.interval, runes, xs, layout.Width, test.runes, test.xs, test.width)
+		}
+	}
+}
+
+func TestTabStops(t *testing.T) {
+	atlas := loadVcr(t)
+	space := atlas.Glyphs[' '].Advance
+	letter := atlas.Glyphs['a'].Advance
+	tests := []struct {
+		text    string
+		tabSize int
+		want    float32
+	}{
+		{"\tb", 4, 4 * space},
+		{"a\tb", 4, 4 * space},
+		{"a\tb", 2, 2 * space},
+		{"a\tb", 0, DefaultTabSize * space},
+		{"aaaa\tb", 4, 8 * space},
+		{"a\t\tb", 4, 8 * space},
+	}
+	for _, test := range tests {
+		layout := Layout(test.text, atlas, 1, false, test.tabSize)
+		glyphs := layout.Lines[0].Glyphs
+		last := glyphs[len(glyphs)-1]
+		if last.Rune != 'b' || last.X != test.want {
+			t.Errorf("Layout(%q, tab %d): b at %g, want %g", test.text, test.tabSize, last.X, test.want)
+		}
+		if layout.Width != test.want+letter {
+			t.Errorf("Layout(%q, tab %d): width %g, want %g", test.text, test.tabSize, layout.Width, test.want+letter)
+		}
+	}
+}
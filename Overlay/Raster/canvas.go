@@ -0,0 +1,286 @@
+package Raster
+
+import (
+	"image"
+	"math"
+)
+
+// Vertex is a point in pixel space with its texture coordinate.
+type Vertex struct {
+	X, Y, U, V float32
+}
+
+// Canvas rasterizes triangles and lines into an RGBA image without a GPU.
+// Primitives blend over each other in the order they are drawn.
+type Canvas struct {
+	Target *image.RGBA
+}
+
+func NewCanvas(Width, Height int) *Canvas {
+	return &Canvas{
+		Target: image.NewRGBA(image.Rect(0, 0, Width, Height)),
+	}
+}
+
+// Clear makes every pixel fully transparent.
+func (c *Canvas) Clear() {
+	for i := range c.Target.Pix {
+		c.Target.Pix[i] = 0
+	}
+}
+
+// FillTriangle draws a filled triangle in either winding. When Texture is not
+// nil the color is multiplied by the texel at the interpolated UV.
+func (c *Canvas) FillTriangle(A, B, C Vertex, Color [4]float32, Texture *image.RGBA) {
+	c.FillTriangleShaded(A, B, C, func(U, V float32) [4]float32 {
+		if Texture == nil {
+			return Color
+		}
+		return modulate(Color, Sample(Texture, U, V))
+	})
+}
+
+// FillTriangleShaded draws a filled triangle whose pixels get the color Shade
+// returns for the interpolated UV, the way a fragment shader would.
+func (c *Canvas) FillTriangleShaded(A, B, C Vertex, Shade func(U, V float32) [4]float32) {
+	area := edge(A, B, C.X, C.Y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		B, C = C, B
+		area = -area
+	}
+	bounds := c.Target.Bounds()
+	minX := clampInt(int(math.Floor(float64(min3(A.X, B.X, C.X)))), bounds.Min.X, bounds.Max.X)
+	maxX := clampInt(int(math.Ceil(float64(max3(A.X, B.X, C.X)))), bounds.Min.X, bounds.Max.X)
+	minY := clampInt(int(math.Floor(float64(min3(A.Y, B.Y, C.Y)))), bounds.Min.Y, bounds.Max.Y)
+	maxY := clampInt(int(math.Ceil(float64(max3(A.Y, B.Y, C.Y)))), bounds.Min.Y, bounds.Max.Y)
+
+	for y := minY; y < maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x < maxX; x++ {
+			px := float32(x) + 0.5
+			w0 := edge(B, C, px, py)
+			w1 := edge(C, A, px, py)
+			w2 := edge(A, B, px, py)
+			if !covers(w0, B, C) || !covers(w1, C, A) || !covers(w2, A, B) {
+				continue
+			}
+			u := (w0*A.U + w1*B.U + w2*C.U) / area
+			v := (w0*A.V + w1*B.V + w2*C.V) / area
+			c.plot(x, y, Shade(u, v))
+		}
+	}
+}
+
+// DrawLine draws a one pixel wide line from A to B, excluding the end point
+// like GL_LINES does. Only the part of the line over the target is stepped
+// through, and lines with an end that is not finite are not drawn.
+func (c *Canvas) DrawLine(A, B Vertex, Color [4]float32, Texture *image.RGBA) {
+	for _, v := range [4]float32{A.X, A.Y, B.X, B.Y} {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return
+		}
+	}
+	// One pixel of margin keeps the ends moved by clipping off the target.
+	A, B, ok := clipLine(A, B, c.Target.Bounds().Inset(-1))
+	if !ok {
+		return
+	}
+	dx, dy := B.X-A.X, B.Y-A.Y
+	steps := int(math.Ceil(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy)))))
+	if steps == 0 {
+		return
+	}
+	for i := 0; i < steps; i++ {
+		t := (float32(i) + 0.5) / float32(steps)
+		x := int(math.Floor(float64(A.X + dx*t)))
+		y := int(math.Floor(float64(A.Y + dy*t)))
+		if !(image.Point{X: x, Y: y}).In(c.Target.Bounds()) {
+			continue
+		}
+		color := Color
+		if Texture != nil {
+			color = modulate(color, Sample(Texture, A.U+(B.U-A.U)*t, A.V+(B.V-A.V)*t))
+		}
+		c.plot(x, y, color)
+	}
+}
+
+// clipLine returns the part of the line from A to B inside Rect, or false
+// when the line misses Rect. It is the clipping of Liang and Barsky, with the
+// clipped ends put exactly on the side they were clipped to so lines with
+// huge coordinates do not lose their position to rounding.
+func clipLine(A, B Vertex, Rect image.Rectangle) (Vertex, Vertex, bool) {
+	x, y := float64(A.X), float64(A.Y)
+	dx, dy := float64(B.X)-x, float64(B.Y)-y
+	t0, t1 := 0.0, 1.0
+	side0, side1 := -1, -1
+	for side, limit := range [4][2]float64{
+		{-dx, x - float64(Rect.Min.X)},
+		{dx, float64(Rect.Max.X) - x},
+		{-dy, y - float64(Rect.Min.Y)},
+		{dy, float64(Rect.Max.Y) - y},
+	} {
+		p, q := limit[0], limit[1]
+		if p == 0 {
+			// Parallel to this side: inside or out along all of it.
+			if q < 0 {
+				return A, B, false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			if r > t1 {
+				return A, B, false
+			}
+			if r > t0 {
+				t0, side0 = r, side
+			}
+		} else {
+			if r < t0 {
+				return A, B, false
+			}
+			if r < t1 {
+				t1, side1 = r, side
+			}
+		}
+	}
+	start, end := A, B
+	if side0 >= 0 {
+		start = clipEnd(A, B, t0, side0, Rect)
+	}
+	if side1 >= 0 {
+		end = clipEnd(A, B, t1, side1, Rect)
+	}
+	return start, end, true
+}
+
+// clipEnd returns the vertex at T along the line from A to B, on the side of
+// Rect numbered Side: left, right, top or bottom.
+func clipEnd(A, B Vertex, T float64, Side int, Rect image.Rectangle) Vertex {
+	at := func(a, b float32) float32 {
+		return float32(float64(a) + (float64(b)-float64(a))*T)
+	}
+	v := Vertex{X: at(A.X, B.X), Y: at(A.Y, B.Y), U: at(A.U, B.U), V: at(A.V, B.V)}
+	switch Side {
+	case 0:
+		v.X = float32(Rect.Min.X)
+	case 1:
+		v.X = float32(Rect.Max.X)
+	case 2:
+		v.Y = float32(Rect.Min.Y)
+	case 3:
+		v.Y = float32(Rect.Max.Y)
+	}
+	return v
+}
+
+// Sample returns the bilinearly filtered texel at U, V with repeat wrapping,
+// matching the LINEAR/REPEAT parameters of GlTools.MakeTexture.
+func Sample(Texture *image.RGBA, U, V float32) [4]float32 {
+	size := Texture.Rect.Size()
+	if size.X == 0 || size.Y == 0 {
+		return [4]float32{}
+	}
+	fx := U*float32(size.X) - 0.5
+	fy := V*float32(size.Y) - 0.5
+	x0 := int(math.Floor(float64(fx)))
+	y0 := int(math.Floor(float64(fy)))
+	tx := fx - float32(x0)
+	ty := fy - float32(y0)
+
+	var result [4]float32
+	c00 := texel(Texture, x0, y0)
+	c10 := texel(Texture, x0+1, y0)
+	c01 := texel(Texture, x0, y0+1)
+	c11 := texel(Texture, x0+1, y0+1)
+	for i := 0; i < 4; i++ {
+		top := c00[i] + (c10[i]-c00[i])*tx
+		bottom := c01[i] + (c11[i]-c01[i])*tx
+		result[i] = top + (bottom-top)*ty
+	}
+	return result
+}
+
+func texel(Texture *image.RGBA, x, y int) [4]float32 {
+	size := Texture.Rect.Size()
+	x = ((x % size.X) + size.X) % size.X
+	y = ((y % size.Y) + size.Y) % size.Y
+	i := Texture.PixOffset(Texture.Rect.Min.X+x, Texture.Rect.Min.Y+y)
+	p := Texture.Pix[i : i+4 : i+4]
+	return [4]float32{
+		float32(p[0]) / 255,
+		float32(p[1]) / 255,
+		float32(p[2]) / 255,
+		float32(p[3]) / 255,
+	}
+}
+
+// plot blends color over the pixel with source-over compositing. The target
+// stays premultiplied so it can be encoded or drawn like any other image.
+func (c *Canvas) plot(x, y int, color [4]float32) {
+	alpha := clamp01(color[3])
+	i := c.Target.PixOffset(x, y)
+	p := c.Target.Pix[i : i+4 : i+4]
+	for ch := 0; ch < 3; ch++ {
+		dst := float32(p[ch]) / 255
+		p[ch] = toByte(clamp01(color[ch])*alpha + dst*(1-alpha))
+	}
+	p[3] = toByte(alpha + float32(p[3])/255*(1-alpha))
+}
+
+func edge(a, b Vertex, px, py float32) float32 {
+	return (b.X-a.X)*(py-a.Y) - (b.Y-a.Y)*(px-a.X)
+}
+
+// covers applies the top-left rule so pixels on an edge shared by two
+// triangles are drawn exactly once.
+func covers(w float32, a, b Vertex) bool {
+	if w > 0 {
+		return true
+	}
+	if w < 0 {
+		return false
+	}
+	dx, dy := b.X-a.X, b.Y-a.Y
+	return (dy == 0 && dx > 0) || dy < 0
+}
+
+func modulate(a, b [4]float32) [4]float32 {
+	return [4]float32{a[0] * b[0], a[1] * b[1], a[2] * b[2], a[3] * b[3]}
+}
+
+func toByte(v float32) uint8 {
+	return uint8(clamp01(v)*255 + 0.5)
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
@@ -0,0 +1,80 @@
+package Raster
+
+import (
+	"math"
+	"testing"
+)
+
+var white = [4]float32{1, 1, 1, 1}
+
+// drawn returns the pixels of c that are not transparent.
+func drawn(c *Canvas) map[[2]int]bool {
+	out := map[[2]int]bool{}
+	bounds := c.Target.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if c.Target.RGBAAt(x, y).A != 0 {
+				out[[2]int{x, y}] = true
+			}
+		}
+	}
+	return out
+}
+
+// row is the pixels From up to To on line Y.
+func row(Y, From, To int) map[[2]int]bool {
+	out := map[[2]int]bool{}
+	for x := From; x < To; x++ {
+		out[[2]int{x, Y}] = true
+	}
+	return out
+}
+
+func sameSet(a, b map[[2]int]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p := range a {
+		if !b[p] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDrawLine(t *testing.T) {
+	inf := float32(math.Inf(1))
+	nan := float32(math.NaN())
+	tests := []struct {
+		name string
+		a, b Vertex
+		want map[[2]int]bool
+	}{
+		{"inside", Vertex{X: 1, Y: 4}, Vertex{X: 10, Y: 4}, row(4, 1, 10)},
+		{"backwards", Vertex{X: 10, Y: 4.5}, Vertex{X: 1, Y: 4.5}, row(4, 1, 10)},
+		{"far end", Vertex{X: 0, Y: 4}, Vertex{X: 1e9, Y: 4}, row(4, 0, 16)},
+		{"far start", Vertex{X: -1e9, Y: 8}, Vertex{X: 8, Y: 8}, row(8, 0, 8)},
+		{"across", Vertex{X: -3e38, Y: 2}, Vertex{X: 3e38, Y: 2}, row(2, 0, 16)},
+		{"outside", Vertex{X: -1e9, Y: -5}, Vertex{X: 1e9, Y: -5}, row(0, 0, 0)},
+		{"past a corner", Vertex{X: 20, Y: -10}, Vertex{X: 40, Y: 10}, row(0, 0, 0)},
+		{"nan", Vertex{X: nan, Y: 4}, Vertex{X: 8, Y: 4}, row(0, 0, 0)},
+		{"infinite", Vertex{X: 0, Y: 4}, Vertex{X: inf, Y: 4}, row(0, 0, 0)},
+	}
+	for _, test := range tests {
+		c := NewCanvas(16, 16)
+		c.DrawLine(test.a, test.b, white, nil)
+		if got := drawn(c); !sameSet(got, test.want) {
+			t.Errorf("%s: drew %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDrawLineDiagonal(t *testing.T) {
+	// A clipped diagonal covers the same pixels as the part drawn whole.
+	whole, clipped := NewCanvas(16, 16), NewCanvas(16, 16)
+	whole.DrawLine(Vertex{X: 0, Y: 0}, Vertex{X: 16, Y: 16}, white, nil)
+	clipped.DrawLine(Vertex{X: -1e6, Y: -1e6}, Vertex{X: 1e6, Y: 1e6}, white, nil)
+	if got, want := drawn(clipped), drawn(whole); !sameSet(got, want) || len(want) != 16 {
+		t.Errorf("clipped diagonal drew %v, want %v", got, want)
+	}
+}
@@ -6,15 +6,17 @@ const RendererVertexShader = `
 
 in vec2 Vert;
 in vec2 Uv;
+in vec4 Color;
 
 uniform mat4 Camera;
-uniform mat4 Model;
 
 out vec2 a_uv;
+out vec4 a_color;
 
 void main(){
 	a_uv = Uv;
-	gl_Position = Camera * Model * vec4(Vert.x,Vert.y,0,1);
+	a_color = Color;
+	gl_Position = Camera * vec4(Vert, 0, 1);
 }
 
 `
@@ -22,17 +24,47 @@ void main(){
 const RendererFragmentShader = `
 #version 460
 
-uniform vec4 BaseColor;
 uniform sampler2D tex;
 uniform bool texEnabled;
 
+// Signed distance field text, see Batch.Effect.
+uniform bool sdf;
+uniform float smoothing;
+uniform float outlineWidth;
+uniform vec4 outlineColor;
+uniform vec2 shadowOffset;
+uniform float shadowSoftness;
+uniform vec4 shadowColor;
+
 in vec2 a_uv;
+in vec4 a_color;
 out vec4 OutputColor;
+
+vec4 over(vec4 top, vec4 bottom){
+	float a = top.a + bottom.a * (1 - top.a);
+	if(a == 0){
+		return vec4(0);
+	}
+	return vec4((top.rgb * top.a + bottom.rgb * bottom.a * (1 - top.a)) / a, a);
+}
+
 void main(){
-	if(texEnabled){
-		OutputColor = BaseColor * texture(tex, a_uv);
+	if(sdf){
+		float d = texture(tex, a_uv).a;
+		vec4 color = vec4(a_color.rgb, a_color.a * smoothstep(0.5 - smoothing, 0.5 + smoothing, d));
+		if(outlineWidth > 0){
+			float edge = 0.5 - outlineWidth;
+			color = over(color, vec4(outlineColor.rgb, outlineColor.a * a_color.a * smoothstep(edge - smoothing, edge + smoothing, d)));
+		}
+		if(shadowColor.a > 0){
+			float s = texture(tex, a_uv - shadowOffset).a;
+			color = over(color, vec4(shadowColor.rgb, shadowColor.a * a_color.a * smoothstep(0.5 - shadowSoftness - smoothing, 0.5 + smoothing, s)));
+		}
+		OutputColor = color;
+	} else if(texEnabled){
+		OutputColor = a_color * texture(tex, a_uv);
 	} else{
-		OutputColor = BaseColor;
+		OutputColor = a_color;
 	}
 	
 }
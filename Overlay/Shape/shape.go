@@ -0,0 +1,105 @@
+// Package Shape splits text into grapheme clusters, the units a reader sees
+// as one character, so layout never separates a letter from the marks on
+// it.
+package Shape
+
+import "unicode"
+
+// Cluster is a base rune and the runes that attach to it. Index is the byte
+// offset of the cluster in the text and Size its length in bytes.
+type Cluster struct {
+	Index, Size int
+	Runes       []rune
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// Clusters splits Text into grapheme clusters. It follows the common cases
+// of Unicode text segmentation: combining marks, variation selectors, emoji
+// modifiers and zero width joiners extend the cluster before them, pairs of
+// regional indicators form one flag and "\r\n" stays together.
+func Clusters(Text string) []Cluster {
+	var clusters []Cluster
+	joined := false
+	for i, r := range Text {
+		if n := len(clusters); n > 0 && (joined || extends(clusters[n-1].Runes, r)) {
+			last := &clusters[n-1]
+			last.Runes = append(last.Runes, r)
+			last.Size = i + len(string(r)) - last.Index
+			joined = r == zeroWidthJoiner
+			continue
+		}
+		clusters = append(clusters, Cluster{Index: i, Size: len(string(r)), Runes: []rune{r}})
+		joined = false
+	}
+	return clusters
+}
+
+// extends reports whether r belongs to the cluster of Runes.
+func extends(Runes []rune, r rune) bool {
+	last := Runes[len(Runes)-1]
+	switch {
+	case last == '\r':
+		return r == '\n' && len(Runes) == 1
+	case last == '\n' || r == '\r' || r == '\n':
+		return false
+	case IsMark(r), r == zeroWidthJoiner:
+		return true
+	case isVariationSelector(r):
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		// Emoji skin tone modifiers.
+		return true
+	case isRegionalIndicator(r):
+		return len(Runes) == 1 && isRegionalIndicator(last)
+	}
+	return false
+}
+
+// IsMark reports whether r is a combining mark drawn on the rune before it.
+// Spacing marks (Mc) take room of their own, so they are not marks here and
+// are laid out like letters.
+func IsMark(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+func isVariationSelector(r rune) bool {
+	return r >= 0xFE00 && r <= 0xFE0F || r >= 0xE0100 && r <= 0xE01EF
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// Compose returns the precomposed rune of Base with Mark on it, like é for
+// e and U+0301.
+func Compose(Base, Mark rune) (rune, bool) {
+	pairs := []rune(compositions[Mark])
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] == Base {
+			return pairs[i+1], true
+		}
+	}
+	return 0, false
+}
+
+// Resolve picks the runes to draw for the cluster with a font that has the
+// glyphs Has reports: the base, composed with as many of the marks as the
+// font has precomposed glyphs for, and the marks left to draw over it.
+// Joiners, selectors and modifiers have no glyph of their own and are
+// dropped, so a sequence is drawn as its first rune.
+func (cluster Cluster) Resolve(Has func(rune) bool) (rune, []rune) {
+	base := cluster.Runes[0]
+	var marks []rune
+	for _, r := range cluster.Runes[1:] {
+		if !IsMark(r) || isVariationSelector(r) {
+			continue
+		}
+		if composed, ok := Compose(base, r); ok && len(marks) == 0 && Has(composed) {
+			base = composed
+			continue
+		}
+		marks = append(marks, r)
+	}
+	return base, marks
+}
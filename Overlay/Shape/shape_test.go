@@ -0,0 +1,70 @@
+package Shape
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusters(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Cluster
+	}{
+		{"Привет", []Cluster{
+			{0, 2, []rune{'П'}}, {2, 2, []rune{'р'}}, {4, 2, []rune{'и'}},
+			{6, 2, []rune{'в'}}, {8, 2, []rune{'е'}}, {10, 2, []rune{'т'}},
+		}},
+		{"\u00e9", []Cluster{{0, 2, []rune{'é'}}}},
+		{"e\u0301", []Cluster{{0, 3, []rune{'e', 0x301}}}},
+		{"e\u0301\u0323x", []Cluster{{0, 5, []rune{'e', 0x301, 0x323}}, {5, 1, []rune{'x'}}}},
+		// A spacing mark takes room of its own.
+		{"\u0915\u093f", []Cluster{{0, 3, []rune{0x915}}, {3, 3, []rune{0x93f}}}},
+		{"a\r\nb", []Cluster{{0, 1, []rune{'a'}}, {1, 2, []rune{'\r', '\n'}}, {3, 1, []rune{'b'}}}},
+		{"\U0001F1FA\U0001F1E6\U0001F1FA", []Cluster{
+			{0, 8, []rune{0x1F1FA, 0x1F1E6}}, {8, 4, []rune{0x1F1FA}},
+		}},
+	}
+	for _, test := range tests {
+		if got := Clusters(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Clusters(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestIsMark(t *testing.T) {
+	for _, r := range []rune{0x301, 0x323, 0x20DD} {
+		if !IsMark(r) {
+			t.Errorf("IsMark(%U) = false", r)
+		}
+	}
+	for _, r := range []rune{'e', 'é', 0x93f, 0x903} {
+		if IsMark(r) {
+			t.Errorf("IsMark(%U) = true", r)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	all := func(rune) bool { return true }
+	none := func(rune) bool { return false }
+	tests := []struct {
+		text  string
+		has   func(rune) bool
+		base  rune
+		marks []rune
+	}{
+		{"\u00e9", all, 'é', nil},
+		{"e\u0301", all, 'é', nil},
+		{"e\u0301", none, 'e', []rune{0x301}},
+		// Only the first mark can compose; the rest are drawn over it.
+		{"e\u0301\u0323", all, 'é', []rune{0x323}},
+		{"q\u0301", all, 'q', []rune{0x301}},
+		{"\u2764\ufe0f", all, 0x2764, nil},
+	}
+	for _, test := range tests {
+		base, marks := Clusters(test.text)[0].Resolve(test.has)
+		if base != test.base || !reflect.DeepEqual(marks, test.marks) {
+			t.Errorf("Resolve(%q) = %q %U, want %q %U", test.text, base, marks, test.base, test.marks)
+		}
+	}
+}
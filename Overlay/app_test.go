@@ -0,0 +1,114 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Engine"
+	"bytes"
+	"image"
+	"testing"
+)
+
+func drawA(app *App) {
+	app.SetColor(255, 0, 0, 255)
+	app.Translate(16, 16)
+	app.SetLayer("top")
+	app.Layer("top").SetOpacity(0.5)
+	app.DrawRect(0, 0, 8, 8)
+}
+
+func drawB(app *App) {
+	app.SetColor(0, 0, 255, 255)
+	app.SetWireframeMode()
+	app.DrawRect(2, 2, 8, 8)
+}
+
+func pixels(Frame image.Image) []byte {
+	return Frame.(*image.RGBA).Pix
+}
+
+// TestAppsAreIndependent draws with two headless Apps at once, interleaving
+// their calls, and checks each frame is the one the App draws alone.
+func TestAppsAreIndependent(t *testing.T) {
+	alone := func(Draw func(app *App)) []byte {
+		app := NewHeadless(32, 32)
+		defer app.Dispose()
+		Draw(&app)
+		app.Render()
+		return pixels(app.Frame())
+	}
+	wantA, wantB := alone(drawA), alone(drawB)
+	if bytes.Equal(wantA, wantB) {
+		t.Fatal("a and b draw the same frame")
+	}
+
+	a, b := NewHeadless(32, 32), NewHeadless(32, 32)
+	defer a.Dispose()
+	defer b.Dispose()
+	a.SetColor(255, 0, 0, 255)
+	b.SetColor(0, 0, 255, 255)
+	a.Translate(16, 16)
+	b.SetWireframeMode()
+	a.SetLayer("top")
+	a.Layer("top").SetOpacity(0.5)
+	b.DrawRect(2, 2, 8, 8)
+	a.DrawRect(0, 0, 8, 8)
+
+	if b.GetTransform() != Engine.Identity() {
+		t.Errorf("transform of b = %v", b.GetTransform())
+	}
+	if b.GetLayer().Name() != "" || len(b.Layers()) != 1 {
+		t.Errorf("b draws into layer %q of %d", b.GetLayer().Name(), len(b.Layers()))
+	}
+	if b.state.color == a.state.color || b.state.fill == a.state.fill {
+		t.Errorf("a and b share color or fill: %v %v", a.state, b.state)
+	}
+
+	b.Render()
+	a.Render()
+	if !bytes.Equal(pixels(a.Frame()), wantA) {
+		t.Error("frame of a differs from a drawn alone")
+	}
+	if !bytes.Equal(pixels(b.Frame()), wantB) {
+		t.Error("frame of b differs from b drawn alone")
+	}
+
+	// The next frame of b is empty whatever a still draws.
+	a.DrawRect(0, 0, 8, 8)
+	b.Render()
+	for _, v := range pixels(b.Frame()) {
+		if v != 0 {
+			t.Fatal("second frame of b is not empty")
+		}
+	}
+}
+
+func TestRenderResetsState(t *testing.T) {
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+	app.SetColor(1, 2, 3, 4)
+	app.Translate(1, 1)
+	app.RotateByDeg(30)
+	app.AnchorPoint(0.5, 0.5)
+	app.SetWireframeMode()
+	app.SetLayer("top")
+	app.SetKerning(false)
+	app.SetTextOutline(1, 0, 0, 0, 255)
+	app.SetTabSize(8)
+	app.SetTextAlign(TEXT_ALIGN_CENTER, TEXT_ALIGN_MIDDLE)
+	app.SetLineSpacing(2)
+	app.SetEllipsis(true)
+	app.Push()
+	// Only the color, transform, rotation and anchor last one frame.
+	want := app.state
+	want.color = defaultColor
+	want.transform = Engine.Identity()
+	want.rotation = 0
+	want.anchorPointX, want.anchorPointY = 0, 0
+	app.Render()
+
+	if app.state != want {
+		t.Errorf("state after Render = %+v, want %+v", app.state, want)
+	}
+	if len(app.stack) != 0 {
+		t.Errorf("%d states left on the stack", len(app.stack))
+	}
+}
@@ -0,0 +1,227 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// ImageHandle is an image loaded by App and FontHandle a font. Handles are
+// never reused, so one kept after its asset was released draws nothing
+// instead of another asset. The zero handle is no asset.
+type ImageHandle uint32
+type FontHandle uint32
+
+type AssetKind byte
+
+const (
+	ASSET_KIND_IMAGE AssetKind = iota
+	ASSET_KIND_FONT
+)
+
+func (kind AssetKind) String() string {
+	if kind == ASSET_KIND_FONT {
+		return "font"
+	}
+	return "image"
+}
+
+// AssetInfo describes a live asset for App.ListAssets. Name is the file it
+// was loaded from, RefCount the loads not yet released and Bytes the memory
+// its textures take, plus the pages a dynamic font keeps to draw new glyphs
+// into. A font family has no memory of its own.
+type AssetInfo struct {
+	Kind     AssetKind
+	Handle   uint32
+	Name     string
+	RefCount int
+	Bytes    int
+}
+
+func (info AssetInfo) String() string {
+	return fmt.Sprintf("%s %d %s refs=%d %d KiB", info.Kind, info.Handle, info.Name, info.RefCount, (info.Bytes+1023)/1024)
+}
+
+// asset is the bookkeeping of every loaded image and font: the name
+// ListAssets shows, the key loads of the same file are shared by, how many
+// loads hold it and the bytes of its textures.
+type asset struct {
+	name  string
+	key   string
+	refs  int
+	bytes int
+}
+
+type imageAsset struct {
+	asset
+	texture       uint32
+	width, height int
+}
+
+func (ctx *Context) newHandle() uint32 {
+	ctx.lastHandle++
+	return ctx.lastHandle
+}
+
+// pathKey is the key of the file at Path, the same for every spelling of
+// the path.
+func pathKey(Path string) string {
+	if abs, err := filepath.Abs(Path); err == nil {
+		return abs
+	}
+	return Path
+}
+
+// addImage takes an uploaded texture under a new handle. Key is "" for
+// images that are not shared.
+func (ctx *Context) addImage(Name, Key string, Texture uint32, Width, Height int) ImageHandle {
+	handle := ImageHandle(ctx.newHandle())
+	ctx.images[handle] = &imageAsset{
+		asset:   asset{name: Name, key: Key, refs: 1, bytes: Width * Height * 4},
+		texture: Texture,
+		width:   Width,
+		height:  Height,
+	}
+	if Key != "" {
+		ctx.imageKeys[Key] = handle
+	}
+	return handle
+}
+
+// retainImage returns the image loaded before with Key and counts the new
+// reference to it.
+func (ctx *Context) retainImage(Key string) (ImageHandle, *imageAsset, bool) {
+	handle, ok := ctx.imageKeys[Key]
+	if !ok {
+		return 0, nil, false
+	}
+	img := ctx.images[handle]
+	img.refs++
+	return handle, img, true
+}
+
+func (ctx *Context) releaseImage(Image ImageHandle) {
+	img, ok := ctx.images[Image]
+	if !ok {
+		return
+	}
+	img.refs--
+	if img.refs > 0 {
+		return
+	}
+	ctx.unloadImage(Image, img)
+}
+
+func (ctx *Context) unloadImage(Image ImageHandle, Img *imageAsset) {
+	delete(ctx.images, Image)
+	if Img.key != "" {
+		delete(ctx.imageKeys, Img.key)
+	}
+	ctx.renderer.DeleteTexture(Img.texture)
+}
+
+// addFont takes a font with its uploaded pages under a new handle. Key is
+// "" for fonts that are not shared.
+func (ctx *Context) addFont(Name, Key string, Loaded *loadedFont) FontHandle {
+	handle := FontHandle(ctx.newHandle())
+	Loaded.handle = handle
+	Loaded.name = Name
+	Loaded.key = Key
+	Loaded.refs = 1
+	ctx.drawList.Fonts[handle] = Loaded
+	if Key != "" {
+		ctx.fontKeys[Key] = handle
+	}
+	return handle
+}
+
+func (ctx *Context) retainFont(Key string) (FontHandle, bool) {
+	handle, ok := ctx.fontKeys[Key]
+	if !ok {
+		return 0, false
+	}
+	ctx.drawList.Fonts[handle].refs++
+	return handle, true
+}
+
+// releaseFont drops a reference to Font and unloads it with the last one.
+// A family lets go of its members.
+func (ctx *Context) releaseFont(Font FontHandle) {
+	loaded, ok := ctx.drawList.Fonts[Font]
+	if !ok {
+		return
+	}
+	loaded.refs--
+	if loaded.refs > 0 {
+		return
+	}
+	ctx.unloadFont(loaded)
+	for _, member := range loaded.members {
+		ctx.releaseFont(member.handle)
+	}
+}
+
+func (ctx *Context) unloadFont(Loaded *loadedFont) {
+	delete(ctx.drawList.Fonts, Loaded.handle)
+	if Loaded.key != "" {
+		delete(ctx.fontKeys, Loaded.key)
+	}
+	for name, font := range ctx.fontNames {
+		if font == Loaded.handle {
+			delete(ctx.fontNames, name)
+		}
+	}
+	for _, tex := range Loaded.textures {
+		ctx.renderer.DeleteTexture(tex)
+	}
+	if cache, ok := Loaded.font.(*ttf2atlas.GlyphCache); ok {
+		cache.Close()
+	}
+}
+
+// unloadAll frees every asset whatever its references.
+func (ctx *Context) unloadAll() {
+	for handle, img := range ctx.images {
+		ctx.unloadImage(handle, img)
+	}
+	for _, loaded := range ctx.drawList.Fonts {
+		ctx.unloadFont(loaded)
+	}
+}
+
+// listAssets returns every live asset, images first, each kind by handle.
+func (ctx *Context) listAssets() []AssetInfo {
+	infos := make([]AssetInfo, 0, len(ctx.images)+len(ctx.drawList.Fonts))
+	for handle, img := range ctx.images {
+		infos = append(infos, AssetInfo{
+			Kind:     ASSET_KIND_IMAGE,
+			Handle:   uint32(handle),
+			Name:     img.name,
+			RefCount: img.refs,
+			Bytes:    img.bytes,
+		})
+	}
+	for handle, loaded := range ctx.drawList.Fonts {
+		bytes := loaded.bytes
+		if cache, ok := loaded.font.(*ttf2atlas.GlyphCache); ok {
+			for _, page := range cache.Pages() {
+				bytes += len(page.Pix)
+			}
+		}
+		infos = append(infos, AssetInfo{
+			Kind:     ASSET_KIND_FONT,
+			Handle:   uint32(handle),
+			Name:     loaded.name,
+			RefCount: loaded.refs,
+			Bytes:    bytes,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Kind != infos[j].Kind {
+			return infos[i].Kind < infos[j].Kind
+		}
+		return infos[i].Handle < infos[j].Handle
+	})
+	return infos
+}
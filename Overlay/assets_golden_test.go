@@ -0,0 +1,23 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestSharedImageGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "shared_image", Width: 64, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			// Both loads share one texture, which outlives the first release and
+			// must draw the same as the image case of Golden.Primitives.
+			first, _, _, err := app.LoadImage(assets.ImagePath)
+			Golden.Must(t, err)
+			img, w, h, err := app.LoadImage(assets.ImagePath)
+			Golden.Must(t, err)
+			app.ReleaseImage(first)
+			app.DrawImage(8, 8, float32(w)*3, float32(h)*3, img)
+		},
+	})
+}
@@ -0,0 +1,172 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeImage saves the image of testFS into a new directory and returns its
+// path.
+func writeImage(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dot.png")
+	if err := os.WriteFile(path, testFS(t)["images/dot.png"].Data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func refCounts(app *App) map[uint32]int {
+	counts := map[uint32]int{}
+	for _, info := range app.ListAssets() {
+		counts[info.Handle] = info.RefCount
+	}
+	return counts
+}
+
+func TestImageSharedByPath(t *testing.T) {
+	path := writeImage(t)
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+
+	first, _, _, err := app.LoadImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(filepath.Dir(path), ".", "..", filepath.Base(filepath.Dir(path)), "dot.png")
+	second, _, _, err := app.LoadImage(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatalf("%s and %s loaded as %d and %d", path, other, first, second)
+	}
+	assets := app.ListAssets()
+	if len(assets) != 1 || assets[0].RefCount != 2 || assets[0].Kind != ASSET_KIND_IMAGE || assets[0].Bytes != 4*2*4 {
+		t.Fatalf("assets = %v, want one 4x2 image with 2 references", assets)
+	}
+}
+
+func TestFontSharedByAtlas(t *testing.T) {
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+
+	// Options spelled differently that build the same atlas share it.
+	first, err := app.LoadFontWithOptions(vcrPath, 16, ttf2atlas.Options{Ranges: []ttf2atlas.Range{ttf2atlas.BasicLatin}, Charset: "°", Padding: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := app.LoadFontWithOptions(vcrPath, 16, ttf2atlas.Options{Ranges: []ttf2atlas.Range{{First: 0x50, Last: 0x7E}, {First: '°', Last: '°'}, {First: 0x20, Last: 0x4F}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatalf("loaded as %d and %d", first, second)
+	}
+	if counts := refCounts(&app); counts[uint32(first)] != 2 {
+		t.Errorf("%d references, want 2", counts[uint32(first)])
+	}
+}
+
+func TestReleaseOnLastReference(t *testing.T) {
+	path := writeImage(t)
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+
+	img, _, _, err := app.LoadImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.LoadImage(path)
+	font, err := app.LoadFont(vcrPath, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := app.LoadFont(vcrPath, 16); again != font {
+		t.Fatalf("font loaded twice as %d and %d", font, again)
+	}
+
+	app.ReleaseImage(img)
+	app.ReleaseFont(font)
+	if counts := refCounts(&app); counts[uint32(img)] != 1 || counts[uint32(font)] != 1 {
+		t.Fatalf("after one release: %v", app.ListAssets())
+	}
+	app.ReleaseImage(img)
+	app.ReleaseFont(font)
+	if assets := app.ListAssets(); len(assets) != 0 {
+		t.Fatalf("after the last release: %v", assets)
+	}
+	// Releasing more than was loaded does nothing.
+	app.ReleaseImage(img)
+	app.ReleaseFont(font)
+}
+
+func TestReleaseFamily(t *testing.T) {
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+
+	small, err := app.LoadFont(vcrPath, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := app.LoadDynamicFont(vcrPath, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	family := app.NewFontFamily(small, large)
+
+	// The family holds its members after the loads are released.
+	app.ReleaseFont(small)
+	app.ReleaseFont(large)
+	counts := refCounts(&app)
+	if len(counts) != 3 || counts[uint32(small)] != 1 || counts[uint32(large)] != 1 || counts[uint32(family)] != 1 {
+		t.Fatalf("after releasing the members: %v", app.ListAssets())
+	}
+	app.ReleaseFont(family)
+	if assets := app.ListAssets(); len(assets) != 0 {
+		t.Fatalf("after releasing the family: %v", assets)
+	}
+}
+
+func TestStaleHandleDrawsNothing(t *testing.T) {
+	path := writeImage(t)
+	empty := render(t, func(app *App) error { return nil })
+
+	frame := render(t, func(app *App) error {
+		img, _, _, err := app.LoadImage(path)
+		if err != nil {
+			return err
+		}
+		font, err := app.LoadFont(vcrPath, 16)
+		if err != nil {
+			return err
+		}
+		app.ReleaseImage(img)
+		app.ReleaseFont(font)
+
+		// New loads never get the handles back.
+		newImg, _, _, err := app.LoadImage(path)
+		if err != nil {
+			return err
+		}
+		newFont, err := app.LoadFont(vcrPath, 16)
+		if err != nil {
+			return err
+		}
+		if newImg == img || uint32(newFont) == uint32(img) || newFont == font {
+			t.Errorf("handles reused: image %d then %d, font %d then %d", img, newImg, font, newFont)
+		}
+		app.DrawImage(0, 0, 64, 32, img)
+		app.DrawText(2, 2, 16, 0, 0, font, 1, "Aby")
+		if missing := app.GetMissingRunes(font); len(missing) != 0 {
+			t.Errorf("stale font reports missing runes %U", missing)
+		}
+		return nil
+	})
+	if !bytes.Equal(frame, empty) {
+		t.Error("stale handles drew something")
+	}
+}
@@ -0,0 +1,173 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Engine"
+	"DrawerGO/Overlay/Mesh"
+	"DrawerGO/Overlay/Type"
+	"DrawerGO/Overlay/ttf2atlas"
+	"math"
+	"sort"
+)
+
+var circleMesh = Mesh.Circle(360)
+
+// build adds the vertices of every visible layer to b, layer by layer, and
+// within a layer ordered by ZIndex. The sort is stable, so commands with the
+// same ZIndex keep the order they were added in.
+func (list *DrawList) build(b *Batch.Batch) {
+	for _, layer := range list.sortedLayers() {
+		if !layer.visible || layer.opacity == 0 {
+			continue
+		}
+		commands := layer.commands
+		sort.SliceStable(commands, func(i, j int) bool {
+			return commands[i].order() < commands[j].order()
+		})
+		first := len(b.Vertices)
+		for _, command := range commands {
+			command.appendTo(b, list.Fonts)
+		}
+		if layer.opacity < 1 {
+			for i := first; i < len(b.Vertices); i++ {
+				b.Vertices[i].Color[3] *= layer.opacity
+			}
+		}
+	}
+}
+
+func (v Line) order() uint32        { return v.ZIndex }
+func (v OutlineRect) order() uint32 { return v.ZIndex }
+func (v Rect) order() uint32        { return v.ZIndex }
+func (v Circle) order() uint32      { return v.ZIndex }
+func (v Polygon) order() uint32     { return v.ZIndex }
+func (v Image) order() uint32       { return v.ZIndex }
+func (v Text) order() uint32        { return v.ZIndex }
+
+func (v Line) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	appendMesh(b, Mesh.Line(v.X1, v.Y1, v.X2, v.Y2), v.model(), Type.Line, v.Fill, v.Color, 0, Batch.Effect{})
+}
+func (v OutlineRect) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	appendMesh(b, Mesh.Rect(), v.model(), Type.OutlineRect, true, v.Color, 0, Batch.Effect{})
+}
+func (v Rect) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	appendMesh(b, Mesh.Rect(), v.model(), Type.Rectangle, v.Fill, v.Color, 0, Batch.Effect{})
+}
+func (v Circle) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	appendMesh(b, circleMesh, v.model(), Type.Circle, v.Fill, v.Color, 0, Batch.Effect{})
+}
+func (v Polygon) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	appendMesh(b, Mesh.Polygon(v.X1, v.Y1, v.X2, v.Y2, v.X3, v.Y3), v.model(), Type.Polygon, v.Fill, v.Color, 0, Batch.Effect{})
+}
+func (v Image) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	appendMesh(b, Mesh.Rect(), v.model(), Type.Image, v.Fill, v.Color, v.Image, Batch.Effect{})
+}
+func (v Text) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	loaded, ok := Fonts[v.Font]
+	if !ok || loaded.font.FontMetrics().FontSize == 0 {
+		return
+	}
+	layout := Mesh.Layout(v.Text, loaded.font, v.Interval, v.Kerning, v.TabSize)
+	v.appendPages(b, loaded, Mesh.Glyphs(layout, loaded.font.FontMetrics().Ascent), v.model(layout, loaded.font))
+}
+func (v TextBox) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	loaded, ok := Fonts[v.Font]
+	if !ok {
+		return
+	}
+	metrics := loaded.font.FontMetrics()
+	if metrics.FontSize == 0 {
+		return
+	}
+	scale := v.Size / metrics.FontSize
+	layout := v.box(scale).Layout(v.Text.Text, loaded.font, v.Interval, v.Kerning)
+	v.appendPages(b, loaded, Mesh.Glyphs(layout, metrics.Ascent), v.model(scale))
+}
+
+// appendPages adds the glyph quads of every atlas page of a text.
+func (v Text) appendPages(b *Batch.Batch, loaded *loadedFont, Pages [][]float32, model Engine.Transform) {
+	fontSize := loaded.font.FontMetrics().FontSize
+	for page, data := range Pages {
+		texture, font := loaded.page(page)
+		if texture != 0 {
+			appendMesh(b, data, model, Type.Text, v.Fill, v.Color, texture, v.effect(font, fontSize, model))
+		}
+	}
+}
+
+// effect converts the pixel sizes of the text effects into distance field
+// units for an SDF atlas. One atlas pixel is 1/(2*Spread) of the field and
+// model scales pixels of a font of FontSize, the layout size, to screen
+// pixels. Fonts without a field get the plain effect.
+func (v Text) effect(Font ttf2atlas.Font, FontSize float32, model Engine.Transform) Batch.Effect {
+	atlas, ok := Font.(*ttf2atlas.FontAtlas)
+	if !ok || !atlas.SDF || atlas.Spread <= 0 || atlas.FontSize == 0 {
+		return Batch.Effect{}
+	}
+	scale := float32(math.Sqrt(math.Abs(float64(model.A*model.D - model.B*model.C))))
+	scale *= FontSize / atlas.FontSize
+	if scale == 0 {
+		return Batch.Effect{}
+	}
+	unit := 1 / (2 * atlas.Spread * scale)
+	effect := Batch.Effect{SDF: true, Smoothing: 0.5 * unit}
+	if v.OutlineWidth > 0 && v.OutlineColor[3] > 0 {
+		effect.OutlineWidth = v.OutlineWidth * unit
+		effect.OutlineColor = v.OutlineColor
+	}
+	if v.ShadowColor[3] > 0 {
+		effect.ShadowOffset = [2]float32{
+			v.ShadowOffsetX / scale / float32(atlas.Width),
+			v.ShadowOffsetY / scale / float32(atlas.Height),
+		}
+		effect.ShadowSoftness = v.ShadowSoftness * unit
+		effect.ShadowColor = v.ShadowColor
+	}
+	return effect
+}
+
+// appendMesh transforms X, Y, U, V mesh data with model and adds it to b the
+// way Mode assembles it: loops and fans are unrolled into line and triangle
+// lists, and triangles become their edges when Fill is false.
+func appendMesh(b *Batch.Batch, data []float32, model Engine.Transform, Mode string, Fill bool, Color [4]float32, Texture uint32, Effect Batch.Effect) {
+	vertices := make([]Batch.Vertex, 0, len(data)/4)
+	for i := 0; i+3 < len(data); i += 4 {
+		x, y := model.Apply(data[i], data[i+1])
+		vertices = append(vertices, Batch.Vertex{X: x, Y: y, U: data[i+2], V: data[i+3], Color: Color})
+	}
+	if len(vertices) == 0 {
+		return
+	}
+
+	switch Mode {
+	case Type.Line:
+		b.Lines(Texture, Effect, vertices[:len(vertices)/2*2]...)
+		return
+	case Type.OutlineRect:
+		loop := make([]Batch.Vertex, 0, len(vertices)*2)
+		for i := range vertices {
+			loop = append(loop, vertices[i], vertices[(i+1)%len(vertices)])
+		}
+		b.Lines(Texture, Effect, loop...)
+		return
+	case Type.Circle:
+		fan := make([]Batch.Vertex, 0, len(vertices)*3)
+		for i := 1; i+1 < len(vertices); i++ {
+			fan = append(fan, vertices[0], vertices[i], vertices[i+1])
+		}
+		vertices = fan
+	default:
+		vertices = vertices[:len(vertices)/3*3]
+	}
+
+	if Fill {
+		b.Triangles(Texture, Effect, vertices...)
+		return
+	}
+	edges := make([]Batch.Vertex, 0, len(vertices)*2)
+	for i := 0; i+2 < len(vertices); i += 3 {
+		a, c, d := vertices[i], vertices[i+1], vertices[i+2]
+		edges = append(edges, a, c, c, d, d, a)
+	}
+	b.Lines(Texture, Effect, edges...)
+}
@@ -0,0 +1,18 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestWireframeGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "wireframe", Width: 64, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			app.SetWireframeMode()
+			app.SetColor(255, 255, 255, 255)
+			app.DrawRect(8, 8, 48, 48)
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Engine"
+	"DrawerGO/Overlay/ttf2atlas"
+	"testing"
+)
+
+// TestTextWithoutFontSize checks text in a font of size 0 draws nothing
+// instead of glyphs scaled by infinity.
+func TestTextWithoutFontSize(t *testing.T) {
+	loaded := newLoadedFont(&ttf2atlas.BitmapFont{
+		Pages:  []string{"page.png"},
+		Glyphs: map[rune]ttf2atlas.Glyph{'a': {Width: 4, Height: 4, Advance: 5, U1: 1, V1: 1}},
+	})
+	loaded.textures = []uint32{1}
+	fonts := map[FontHandle]*loadedFont{1: loaded}
+	text := Text{Size: 16, Text: "aa", Transform: Engine.Identity(), Fill: true, Font: 1, Interval: 1, Color: defaultColor}
+
+	var b Batch.Batch
+	text.appendTo(&b, fonts)
+	TextBox{Text: text, BoxWidth: 64, BoxHeight: 64, LineSpacing: 1}.appendTo(&b, fonts)
+	if len(b.Vertices) != 0 {
+		t.Fatalf("drew %d vertices, first %+v", len(b.Vertices), b.Vertices[0])
+	}
+
+	loaded.font.(*ttf2atlas.BitmapFont).FontSize = 16
+	text.appendTo(&b, fonts)
+	if len(b.Vertices) == 0 {
+		t.Fatal("drew nothing with a font size")
+	}
+}
@@ -0,0 +1,67 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+)
+
+// The kinds of failure a LoadError can have; test for them with errors.Is.
+// Errors of GL shaders are a *GlTools.ShaderError, found with errors.As.
+var (
+	ErrNotFound          = fs.ErrNotExist
+	ErrDecode            = ttf2atlas.ErrDecode
+	ErrUnsupportedFormat = ttf2atlas.ErrUnsupportedFormat
+	ErrInvalidRange      = ttf2atlas.ErrInvalidRange
+)
+
+// LoadError is returned by the functions that load images and fonts. Op is
+// what failed, like "load font", and Path the file it was loading, empty
+// when it was loaded from memory.
+type LoadError struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (err *LoadError) Error() string {
+	if err.Path == "" {
+		return fmt.Sprintf("%s: %v", err.Op, err.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", err.Op, err.Path, err.Err)
+}
+
+func (err *LoadError) Unwrap() error {
+	return err.Err
+}
+
+// InitError is returned by New when the window, OpenGL or the shaders of the
+// renderer cannot be set up. Op is the step that failed.
+type InitError struct {
+	Op  string
+	Err error
+}
+
+func (err *InitError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Op, err.Err)
+}
+
+func (err *InitError) Unwrap() error {
+	return err.Err
+}
+
+// imageError gives the error of image.Decode its kind; file errors keep
+// theirs.
+func imageError(Err error) error {
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(Err, &pathErr):
+		return Err
+	case errors.Is(Err, image.ErrFormat):
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, Err)
+	default:
+		return fmt.Errorf("%w: %v", ErrDecode, Err)
+	}
+}
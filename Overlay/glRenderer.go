@@ -0,0 +1,200 @@
+//go:build windows
+
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Engine"
+	"DrawerGO/Overlay/GlTools"
+	"DrawerGO/Overlay/Shader"
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/gonutz/w32/v2"
+	"image"
+)
+
+// glRenderer draws the frame with OpenGL into the transparent overlay window.
+// The whole frame is batched into one streaming vertex buffer and drawn in
+// command order with one call per texture or mode change; there is no depth
+// test, later vertices simply blend over earlier ones.
+type glRenderer struct {
+	mainProgram uint32
+	buffer      GlTools.StreamBuffer
+	batch       Batch.Batch
+
+	window  *glfw.Window
+	hwnd    w32.HWND
+	version string
+
+	cameraUniform, textureUniform, textureEnabledUniform int32
+	sdfUniforms                                          sdfUniforms
+	vertexAttribute, uvAttribute, colorAttribute         GlTools.Attribute
+}
+
+// initGl loads OpenGL for the current context and returns its version.
+func initGl() (string, error) {
+	if err := gl.Init(); err != nil {
+		return "", err
+	}
+	version := gl.GoStr(gl.GetString(gl.VERSION))
+
+	gl.FrontFace(gl.FRONT_FACE)
+	gl.Enable(gl.LINE_SMOOTH)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	return version, nil
+}
+
+func newGlRenderer(window *glfw.Window, hwnd w32.HWND, version string) *glRenderer {
+	return &glRenderer{
+		mainProgram: 0,
+		buffer:      GlTools.NewStreamBuffer(),
+		window:      window,
+		hwnd:        hwnd,
+		version:     version,
+	}
+}
+
+// Init builds the shader program. Its error is a *GlTools.ShaderError with
+// the info log of the driver.
+func (r *glRenderer) Init() error {
+	vertShader, err := GlTools.CompileShader(Shader.RendererVertexShader, gl.VERTEX_SHADER)
+	if err != nil {
+		return err
+	}
+	defer gl.DeleteShader(vertShader)
+	fragShader, err := GlTools.CompileShader(Shader.RendererFragmentShader, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return err
+	}
+	defer gl.DeleteShader(fragShader)
+	prog, err := GlTools.NewProgram(vertShader, fragShader)
+
+	if err != nil {
+		return err
+	}
+	r.mainProgram = prog
+
+	r.cameraUniform = gl.GetUniformLocation(prog, gl.Str("Camera\x00"))
+	r.textureUniform = gl.GetUniformLocation(prog, gl.Str("tex\x00"))
+	r.textureEnabledUniform = gl.GetUniformLocation(prog, gl.Str("texEnabled\x00"))
+	r.sdfUniforms = sdfUniforms{
+		sdf:            gl.GetUniformLocation(prog, gl.Str("sdf\x00")),
+		smoothing:      gl.GetUniformLocation(prog, gl.Str("smoothing\x00")),
+		outlineWidth:   gl.GetUniformLocation(prog, gl.Str("outlineWidth\x00")),
+		outlineColor:   gl.GetUniformLocation(prog, gl.Str("outlineColor\x00")),
+		shadowOffset:   gl.GetUniformLocation(prog, gl.Str("shadowOffset\x00")),
+		shadowSoftness: gl.GetUniformLocation(prog, gl.Str("shadowSoftness\x00")),
+		shadowColor:    gl.GetUniformLocation(prog, gl.Str("shadowColor\x00")),
+	}
+	vertexAttributeLocation := uint32(gl.GetAttribLocation(prog, gl.Str("Vert\x00")))
+	uvAttributeLocation := uint32(gl.GetAttribLocation(prog, gl.Str("Uv\x00")))
+	colorAttributeLocation := uint32(gl.GetAttribLocation(prog, gl.Str("Color\x00")))
+
+	r.vertexAttribute = GlTools.NewAttribute(vertexAttributeLocation, 2, 0)
+	r.uvAttribute = GlTools.NewAttribute(uvAttributeLocation, 2, 2)
+	r.colorAttribute = GlTools.NewAttribute(colorAttributeLocation, 4, 4)
+	return nil
+}
+
+func (r *glRenderer) UploadTexture(Img image.Image) (uint32, int, int, error) {
+	tex := GlTools.MakeTexture(true)
+	width, height, err := GlTools.UploadTextureFromImage(tex, Img)
+	if err != nil {
+		gl.DeleteTextures(1, &tex)
+		return 0, 0, 0, err
+	}
+	return tex, width, height, nil
+}
+
+func (r *glRenderer) UpdateTexture(TextureId uint32, Img image.Image) error {
+	_, _, err := GlTools.UploadTextureFromImage(TextureId, Img)
+	return err
+}
+
+func (r *glRenderer) DeleteTexture(TextureId uint32) {
+	gl.DeleteTextures(1, &TextureId)
+}
+
+func (r *glRenderer) Dispose() {
+	r.buffer.Delete()
+	gl.DeleteProgram(r.mainProgram)
+}
+
+func (r *glRenderer) Render(List *DrawList) {
+	w32.SetWindowPos(
+		r.hwnd,
+		w32.HWND_TOPMOST,
+		0, 0, 0, 0,
+		w32.SWP_NOMOVE|w32.SWP_NOSIZE|w32.SWP_SHOWWINDOW,
+	)
+
+	width, height := r.window.GetSize()
+
+	ortho := mgl32.Ortho2D(0, float32(width), float32(height), 0)
+
+	gl.Viewport(0, 0, int32(width), int32(height))
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	gl.ClearColor(0, 0, 0, 0)
+
+	r.batch.Reset()
+	List.build(&r.batch)
+	if len(r.batch.Vertices) > 0 {
+		r.buffer.Upload(r.batch.Vertices, len(r.batch.Vertices)*Engine.VertexSize*Engine.FloatSize)
+
+		gl.UseProgram(r.mainProgram)
+		gl.UniformMatrix4fv(r.cameraUniform, 1, false, &ortho[0])
+		gl.Uniform1i(r.textureUniform, 0)
+		r.buffer.Begin()
+		r.vertexAttribute.Use()
+		r.uvAttribute.Use()
+		r.colorAttribute.Use()
+		gl.ActiveTexture(gl.TEXTURE0)
+		for _, call := range r.batch.Calls {
+			r.draw(call)
+		}
+		r.buffer.End()
+		gl.UseProgram(0)
+	}
+
+	glfw.PollEvents()
+	r.window.SwapBuffers()
+}
+
+// draw issues one batched draw call.
+func (r *glRenderer) draw(call Batch.Call) {
+	gl.BindTexture(gl.TEXTURE_2D, call.Texture)
+	if call.Texture != 0 {
+		gl.Uniform1i(r.textureEnabledUniform, 1)
+	} else {
+		gl.Uniform1i(r.textureEnabledUniform, 0)
+	}
+	r.sdfUniforms.set(call.Effect)
+	mode := uint32(gl.TRIANGLES)
+	if call.Mode == Batch.Lines {
+		mode = gl.LINES
+	}
+	gl.DrawArrays(mode, int32(call.First), int32(call.Count))
+}
+
+// sdfUniforms are the locations of the Batch.Effect uniforms of the fragment
+// shader.
+type sdfUniforms struct {
+	sdf, smoothing, outlineWidth, outlineColor int32
+	shadowOffset, shadowSoftness, shadowColor  int32
+}
+
+func (u sdfUniforms) set(Effect Batch.Effect) {
+	if !Effect.SDF {
+		gl.Uniform1i(u.sdf, 0)
+		return
+	}
+	gl.Uniform1i(u.sdf, 1)
+	gl.Uniform1f(u.smoothing, Effect.Smoothing)
+	gl.Uniform1f(u.outlineWidth, Effect.OutlineWidth)
+	gl.Uniform4fv(u.outlineColor, 1, &Effect.OutlineColor[0])
+	gl.Uniform2fv(u.shadowOffset, 1, &Effect.ShadowOffset[0])
+	gl.Uniform1f(u.shadowSoftness, Effect.ShadowSoftness)
+	gl.Uniform4fv(u.shadowColor, 1, &Effect.ShadowColor[0])
+}
@@ -0,0 +1,95 @@
+package Overlay
+
+// Layer is a named group of draw calls. Layers are drawn by Order, lowest
+// first, and layers with the same Order in the order they were created. A
+// layer keeps its settings across frames; only what was drawn into it is
+// cleared by App.Render.
+//
+// Every App has a default layer named "" with Order 0 that draw calls go to
+// until SetLayer picks another one.
+type Layer struct {
+	name     string
+	order    int
+	visible  bool
+	opacity  float32
+	commands []Primitive
+}
+
+func newLayer(Name string) *Layer {
+	return &Layer{
+		name:     Name,
+		visible:  true,
+		opacity:  1,
+		commands: []Primitive{},
+	}
+}
+
+func (layer *Layer) Name() string {
+	return layer.name
+}
+
+func (layer *Layer) SetOrder(Order int) {
+	layer.order = Order
+}
+func (layer *Layer) GetOrder() int {
+	return layer.order
+}
+
+// SetVisible hides or shows the layer. Draw calls made into a hidden layer
+// are still accepted but not rendered.
+func (layer *Layer) SetVisible(Visible bool) {
+	layer.visible = Visible
+}
+func (layer *Layer) IsVisible() bool {
+	return layer.visible
+}
+
+// SetOpacity multiplies the alpha of everything in the layer by Opacity,
+// clamped to 0..1. It applies to each primitive on its own, so overlapping
+// primitives of one layer still show through each other.
+func (layer *Layer) SetOpacity(Opacity float32) {
+	if Opacity < 0 {
+		Opacity = 0
+	}
+	if Opacity > 1 {
+		Opacity = 1
+	}
+	layer.opacity = Opacity
+}
+func (layer *Layer) GetOpacity() float32 {
+	return layer.opacity
+}
+
+func (layer *Layer) add(Command Primitive) {
+	layer.commands = append(layer.commands, Command)
+}
+
+/*
+
+App
+
+*/
+
+// Layer returns the layer called Name, creating it on first use.
+func (app *App) Layer(Name string) *Layer {
+	return app.context.drawList.layer(Name)
+}
+
+// SetLayer makes the following draw calls go to the layer called Name. It
+// is part of the state saved by Push, so a subsystem can draw into its own
+// layer inside With without affecting the caller. The layer stays selected
+// across frames.
+func (app *App) SetLayer(Name string) {
+	app.state.layer = app.Layer(Name)
+}
+func (app *App) GetLayer() *Layer {
+	return app.state.layer
+}
+func (app *App) ResetLayer() {
+	app.state.layer = app.context.drawList.layer("")
+}
+
+// Layers returns every layer of the App in the order they are drawn.
+func (app *App) Layers() []*Layer {
+	return app.context.drawList.sortedLayers()
+}
@@ -0,0 +1,46 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestOrderGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "order", Width: 64, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			app.SetColor(0, 255, 0, 255)
+			app.DrawLine(4, 32, 60, 32)
+			app.SetColor(255, 0, 0, 128)
+			app.DrawRect(16, 16, 32, 32)
+			app.SetColor(0, 0, 255, 255)
+			app.DrawCircle(24, 24, 16, 16)
+			app.SetColor(255, 255, 255, 96)
+			app.DrawRect(8, 28, 48, 8)
+		},
+	})
+}
+
+func TestLayersGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "layers", Width: 64, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			app.Layer("hud").SetOrder(1)
+			app.Layer("debug").SetVisible(false)
+			app.Layer("faded").SetOpacity(0.5)
+
+			app.SetLayer("hud")
+			app.SetColor(255, 255, 0, 255)
+			app.DrawRect(24, 8, 16, 48)
+			app.SetLayer("debug")
+			app.DrawRect(0, 0, 64, 64)
+			app.SetLayer("faded")
+			app.SetColor(0, 0, 255, 255)
+			app.DrawRect(8, 40, 48, 16)
+			app.ResetLayer()
+			app.SetColor(255, 0, 0, 255)
+			app.DrawRect(8, 8, 48, 16)
+		},
+	})
+}
@@ -0,0 +1,104 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Engine"
+	"reflect"
+	"testing"
+)
+
+// idRect is a filled rect whose red channel is Id, so the order it is built
+// in can be read back from the vertex colors.
+func idRect(Id float32, ZIndex uint32) Rect {
+	return Rect{Width: 1, Height: 1, Color: [4]float32{Id, 0, 0, 0.8}, Transform: Engine.Identity(), ZIndex: ZIndex, Fill: true}
+}
+
+// builtIds returns the ids of the rects in b in the order they were built,
+// and the alpha each was built with.
+func builtIds(b *Batch.Batch) ([]float32, []float32) {
+	var ids, alphas []float32
+	for i, vertex := range b.Vertices {
+		if i > 0 && vertex.Color == b.Vertices[i-1].Color {
+			continue
+		}
+		ids = append(ids, vertex.Color[0])
+		alphas = append(alphas, vertex.Color[3])
+	}
+	return ids, alphas
+}
+
+func TestBuildSubmissionOrder(t *testing.T) {
+	list := &DrawList{Fonts: map[FontHandle]*loadedFont{}}
+	base := list.layer("")
+
+	// The ZIndex decides, and equal ZIndex keeps the order of submission.
+	base.add(idRect(3, 9))
+	base.add(idRect(1, 2))
+	base.add(idRect(2, 2))
+
+	var b Batch.Batch
+	list.build(&b)
+	ids, _ := builtIds(&b)
+	if want := []float32{1, 2, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("built %v, want %v", ids, want)
+	}
+}
+
+func TestBuildLayerOrder(t *testing.T) {
+	list := &DrawList{Fonts: map[FontHandle]*loadedFont{}}
+	top := list.layer("top")
+	top.SetOrder(1)
+	base := list.layer("")
+	same := list.layer("same")
+	under := list.layer("under")
+	under.SetOrder(-1)
+
+	// Layers are built by order whatever the ZIndex of their commands.
+	base.add(idRect(1, 9))
+	top.add(idRect(4, 1))
+	// Layers of the same order are built in the order they were created.
+	same.add(idRect(2, 1))
+	same.add(idRect(3, 1))
+	under.add(idRect(0.5, 20))
+
+	var b Batch.Batch
+	list.build(&b)
+	ids, _ := builtIds(&b)
+	if want := []float32{0.5, 1, 2, 3, 4}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("built %v, want %v", ids, want)
+	}
+}
+
+func TestBuildVisibilityAndOpacity(t *testing.T) {
+	list := &DrawList{Fonts: map[FontHandle]*loadedFont{}}
+	list.layer("").add(idRect(1, 1))
+	hidden := list.layer("hidden")
+	hidden.add(idRect(2, 2))
+	hidden.SetVisible(false)
+	clear := list.layer("clear")
+	clear.add(idRect(3, 3))
+	clear.SetOpacity(0)
+	faded := list.layer("faded")
+	faded.add(idRect(4, 4))
+	faded.SetOpacity(0.5)
+	over := list.layer("over")
+	over.add(idRect(5, 5))
+	over.SetOpacity(2)
+
+	var b Batch.Batch
+	list.build(&b)
+	ids, alphas := builtIds(&b)
+	if !reflect.DeepEqual(ids, []float32{1, 4, 5}) || !reflect.DeepEqual(alphas, []float32{0.8, 0.4, 0.8}) {
+		t.Errorf("built %v with alpha %v, want [1 4 5] with alpha [0.8 0.4 0.8]", ids, alphas)
+	}
+
+	// Hiding is kept across frames but the commands are not.
+	list.Clear()
+	b.Reset()
+	hidden.add(idRect(2, 2))
+	hidden.SetVisible(true)
+	list.build(&b)
+	if ids, _ := builtIds(&b); !reflect.DeepEqual(ids, []float32{2}) {
+		t.Errorf("after Clear built %v, want [2]", ids)
+	}
+}
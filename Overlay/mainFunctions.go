@@ -1,471 +1,516 @@
 package Overlay
 
 import (
-	"DrawerGO/Overlay/GlTools"
+	"DrawerGO/Overlay/Engine"
 	"DrawerGO/Overlay/Mesh"
-	"DrawerGO/Overlay/Shader"
-	"DrawerGO/Overlay/Type"
 	"DrawerGO/Overlay/ttf2atlas"
+	"errors"
 	"fmt"
-	"github.com/go-gl/gl/v4.6-core/gl"
-	"github.com/go-gl/glfw/v3.3/glfw"
-	"github.com/go-gl/mathgl/mgl32"
-	"github.com/gonutz/w32/v2"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
-	"unsafe"
+	"time"
 )
 
-type vec2 struct {
-	X, Y int
+// loadedFont is a font and the textures of its atlas pages. A font family
+// has no textures of its own, its pages are those of its members in order.
+// missing collects the runes DrawText could not draw.
+type loadedFont struct {
+	asset
+	handle   FontHandle
+	font     ttf2atlas.Font
+	textures []uint32
+	members  []*loadedFont
+	missing  map[rune]bool
 }
-type atlasFont struct {
-	Size     vec2
-	FontSize float32
-}
-
-func GetDisplaySize() (int32, int32) {
-	hMonitor := w32.MonitorFromPoint(0, 0, w32.MONITOR_DEFAULTTOPRIMARY)
 
-	var monitorInfo w32.MONITORINFO
-	monitorInfo.CbSize = uint32(unsafe.Sizeof(monitorInfo))
-	w32.GetMonitorInfo(hMonitor, &monitorInfo)
+func newLoadedFont(Font ttf2atlas.Font) *loadedFont {
+	return &loadedFont{font: Font, missing: map[rune]bool{}}
+}
 
-	width := monitorInfo.RcMonitor.Right - monitorInfo.RcMonitor.Left
-	height := monitorInfo.RcMonitor.Bottom - monitorInfo.RcMonitor.Top
-	return width, height
+// upload adds Img as the next page of the font.
+func (loaded *loadedFont) upload(Renderer Renderer, Img image.Image) error {
+	tex, width, height, err := Renderer.UploadTexture(Img)
+	if err != nil {
+		return err
+	}
+	loaded.textures = append(loaded.textures, tex)
+	loaded.bytes += width * height * 4
+	return nil
 }
 
-func initGlfw(name string) (*glfw.Window, w32.HWND) {
-	if err := glfw.Init(); err != nil {
-		panic(err)
+// uploadAll uploads Pages in order, or none of them when one fails.
+func (loaded *loadedFont) uploadAll(Renderer Renderer, Pages ...image.Image) error {
+	for _, page := range Pages {
+		if err := loaded.upload(Renderer, page); err != nil {
+			for _, tex := range loaded.textures {
+				Renderer.DeleteTexture(tex)
+			}
+			loaded.textures, loaded.bytes = nil, 0
+			return err
+		}
 	}
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 6)
-	glfw.WindowHint(glfw.Samples, 2)
-	glfw.WindowHint(glfw.Decorated, glfw.False)
-	glfw.WindowHint(glfw.TransparentFramebuffer, glfw.True)
-	glfw.WindowHint(glfw.Focused, glfw.False)
-	glfw.WindowHint(glfw.FocusOnShow, glfw.False)
+	return nil
+}
 
-	w, h := GetDisplaySize()
-	window, err := glfw.CreateWindow(int(w), int(h+1), name, nil, nil)
-	if err != nil {
-		panic(err)
-	}
-	window.MakeContextCurrent()
-	window.SetAttrib(glfw.Floating, glfw.True)
-
-	window.Focus()
-	hwnd := w32.GetActiveWindow()
-	w32.SetWindowLong(
-		hwnd,
-		w32.GWL_EXSTYLE,
-		w32.WS_EX_TRANSPARENT|w32.WS_EX_LAYERED|w32.WS_EX_TOOLWINDOW,
-	)
-	w32.SetWindowPos(
-		hwnd,
-		w32.HWND_TOPMOST,
-		0, 0, 0, 0,
-		w32.SWP_NOMOVE|w32.SWP_NOSIZE,
-	)
-	window.Show()
-
-	return window, hwnd
-}
-func initGl() {
-	if err := gl.Init(); err != nil {
-		panic(err)
-	}
-	version := gl.GoStr(gl.GetString(gl.VERSION))
-	fmt.Println("Open GL: ", version)
-
-	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LEQUAL)
-	gl.FrontFace(gl.FRONT_FACE)
-	gl.Enable(gl.LINE_SMOOTH)
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+// page returns the texture of page Page and the font that owns it, or a
+// texture of 0 when the page has not been uploaded.
+func (loaded *loadedFont) page(Page int) (uint32, ttf2atlas.Font) {
+	if loaded.members == nil {
+		if Page < 0 || Page >= len(loaded.textures) {
+			return 0, loaded.font
+		}
+		return loaded.textures[Page], loaded.font
+	}
+	for _, member := range loaded.members {
+		if count := member.font.PageCount(); Page >= count {
+			Page -= count
+			continue
+		}
+		return member.page(Page)
+	}
+	return 0, nil
 }
 
 type Line struct {
-	X1, Y1, X2, Y2                                     float32
-	Color                                              [4]float32
-	Rotation                                           float32
-	TransformX, TransformY, AnchorPointX, AnchorPointY float32
-	ZIndex                                             uint32
-	Fill                                               bool
+	X1, Y1, X2, Y2             float32
+	Color                      [4]float32
+	Rotation                   Engine.Angle
+	AnchorPointX, AnchorPointY float32
+	Transform                  Engine.Transform
+	ZIndex                     uint32
+	Fill                       bool
 }
 type Rect struct {
-	X, Y, Width, Height                                float32
-	Color                                              [4]float32
-	Rotation                                           float32
-	TransformX, TransformY, AnchorPointX, AnchorPointY float32
-	ZIndex                                             uint32
-	Fill                                               bool
+	X, Y, Width, Height        float32
+	Color                      [4]float32
+	Rotation                   Engine.Angle
+	AnchorPointX, AnchorPointY float32
+	Transform                  Engine.Transform
+	ZIndex                     uint32
+	Fill                       bool
 }
 type Image struct {
-	X, Y, Width, Height                                float32
-	Image                                              uint32
-	Color                                              [4]float32
-	Rotation                                           float32
-	TransformX, TransformY, AnchorPointX, AnchorPointY float32
-	ZIndex                                             uint32
-	Fill                                               bool
+	X, Y, Width, Height        float32
+	Image                      uint32
+	Color                      [4]float32
+	Rotation                   Engine.Angle
+	AnchorPointX, AnchorPointY float32
+	Transform                  Engine.Transform
+	ZIndex                     uint32
+	Fill                       bool
 }
 type Polygon struct {
-	X1, Y1, X2, Y2, X3, Y3                             float32
-	Color                                              [4]float32
-	Rotation                                           float32
-	TransformX, TransformY, AnchorPointX, AnchorPointY float32
-	ZIndex                                             uint32
-	Fill                                               bool
+	X1, Y1, X2, Y2, X3, Y3     float32
+	Color                      [4]float32
+	Rotation                   Engine.Angle
+	AnchorPointX, AnchorPointY float32
+	Transform                  Engine.Transform
+	ZIndex                     uint32
+	Fill                       bool
 }
 type Text struct {
-	X, Y, Size                                         float32
-	Text                                               string
-	Color                                              [4]float32
-	Rotation                                           float32
-	TransformX, TransformY, AnchorPointX, AnchorPointY float32
-	ZIndex                                             uint32
-	Fill                                               bool
-	FontTexture                                        uint32
-	Width, Height                                      float32
-	Interval                                           float32
+	X, Y, Size                   float32
+	Text                         string
+	Color                        [4]float32
+	Rotation                     Engine.Angle
+	AnchorPointX, AnchorPointY   float32
+	Transform                    Engine.Transform
+	ZIndex                       uint32
+	Fill                         bool
+	Font                         FontHandle
+	Width, Height                float32
+	Interval                     float32
+	Kerning                      bool
+	TabSize                      int
+	OutlineWidth                 float32
+	OutlineColor                 [4]float32
+	ShadowOffsetX, ShadowOffsetY float32
+	ShadowSoftness               float32
+	ShadowColor                  [4]float32
 }
+
+// TextBox is a Text laid out in a BoxWidth x BoxHeight rectangle, see
+// App.DrawTextBox.
+type TextBox struct {
+	Text
+	BoxWidth, BoxHeight float32
+	Align               TextAlign
+	VerticalAlign       TextVerticalAlign
+	LineSpacing         float32
+	Ellipsis            bool
+}
+
+// RichText is text made of runs with their own font, size, color and
+// style, see App.DrawRichText. The fields of Text apply to the whole block,
+// except Text, Color, Size and Font, which the runs override.
+type RichText struct {
+	Text
+	Runs []richRun
+}
+
+type richRun struct {
+	text                                   string
+	font                                   FontHandle
+	size                                   float32
+	color                                  [4]float32
+	bold, italic, underline, strikethrough bool
+}
+
 type Circle struct {
-	X, Y, TransformX, TransformY, ScaleX, ScaleY, AnchorPointX, AnchorPointY, Rotation float32
-	Color                                                                              [4]float32
-	ZIndex                                                                             uint32
-	Fill                                                                               bool
+	X, Y, ScaleX, ScaleY, AnchorPointX, AnchorPointY float32
+	Rotation                                         Engine.Angle
+	Transform                                        Engine.Transform
+	Color                                            [4]float32
+	ZIndex                                           uint32
+	Fill                                             bool
 }
 type OutlineRect struct {
-	X, Y, Width, Height                                float32
-	Color                                              [4]float32
-	Rotation                                           float32
-	TransformX, TransformY, AnchorPointX, AnchorPointY float32
-	ZIndex                                             uint32
+	X, Y, Width, Height        float32
+	Color                      [4]float32
+	Rotation                   Engine.Angle
+	AnchorPointX, AnchorPointY float32
+	Transform                  Engine.Transform
+	ZIndex                     uint32
 }
 type Context struct {
-	lines        []Line
-	rects        []Rect
-	images       []Image
-	polygons     []Polygon
-	texts        []Text
-	circles      []Circle
-	outlineRects []OutlineRect
-	mainProgram  uint32
-
-	lineRenderObject,
-	rectRenderObject,
-	imageRenderObject,
-	polygonRenderObject,
-	circleRenderObject, outlineRectRenderObject, textRenderObject GlTools.RenderObject
-
-	window *glfw.Window
-
-	colorUniform, modelUniform, cameraUniform, textureUniform, textureEnabledUniform int32
-	vertexAttributeLocation, uvAttributeLocation                                     uint32
-	vertexAttribute, uvAttribute                                                     GlTools.Attribute
-	hwnd                                                                             w32.HWND
-	lastTime, deltaTime, fps                                                         float32
-	loadedFonts                                                                      map[uint32]atlasFont
-}
-
-type Window struct {
-	name       string
-	hwnd       w32.HWND
-	glfwWindow *glfw.Window
+	drawList   DrawList
+	renderer   Renderer
+	fontNames  map[string]FontHandle
+	fontCache  string
+	images     map[ImageHandle]*imageAsset
+	imageKeys  map[string]ImageHandle
+	fontKeys   map[string]FontHandle
+	lastHandle uint32
+
+	startTime                time.Time
+	lastTime, deltaTime, fps float32
 }
+
 type App struct {
 	context Context
 	window  Window
+	state   drawState
+	stack   []drawState
+	zIndex  uint32
 	isRun   bool
 }
 
-func newContext(window *glfw.Window, hwnd w32.HWND) Context {
-	line := GlTools.NewRenderObject(Type.Line)
+// drawState is what the next draw call of an App picks up: color, transform,
+// rotation, anchor, fill mode, kerning, text effects, text box layout and
+// layer. Push and Pop save and restore it as a whole.
+type drawState struct {
+	color                      [4]float32
+	transform                  Engine.Transform
+	rotation                   Engine.Angle
+	anchorPointX, anchorPointY float32
+	fill                       bool
+	kerning                    bool
+	tabSize                    int
+	textEffects                textEffects
+	textBox                    textBoxState
+	layer                      *Layer
+}
 
-	rect := GlTools.NewRenderObject(Type.Rectangle)
-	rect.UploadMesh(Mesh.Rect())
+// textBoxState is how DrawTextBox lays text out.
+type textBoxState struct {
+	align         TextAlign
+	verticalAlign TextVerticalAlign
+	lineSpacing   float32
+	ellipsis      bool
+}
 
-	circle := GlTools.NewRenderObject(Type.Circle)
-	circle.UploadMesh(Mesh.Circle(360))
+// textEffects are the outline and shadow DrawText gives SDF fonts.
+type textEffects struct {
+	outlineWidth                 float32
+	outlineColor                 [4]float32
+	shadowOffsetX, shadowOffsetY float32
+	shadowSoftness               float32
+	shadowColor                  [4]float32
+}
+
+func newDrawState(Layer *Layer) drawState {
+	return drawState{
+		color:     defaultColor,
+		transform: Engine.Identity(),
+		fill:      true,
+		kerning:   true,
+		tabSize:   Mesh.DefaultTabSize,
+		textBox:   textBoxState{lineSpacing: 1},
+		layer:     Layer,
+	}
+}
 
-	polygon := GlTools.NewRenderObject(Type.Polygon)
+func newContext(renderer Renderer) Context {
+	return Context{
+		drawList: DrawList{
+			Layers: []*Layer{newLayer("")},
+			Fonts:  map[FontHandle]*loadedFont{},
+		},
+		renderer:  renderer,
+		fontNames: map[string]FontHandle{},
+		images:    map[ImageHandle]*imageAsset{},
+		imageKeys: map[string]ImageHandle{},
+		fontKeys:  map[string]FontHandle{},
+		startTime: time.Now(),
+	}
+}
+func (ctx *Context) Render() error {
+	var now = ctx.GetTime()
+	ctx.deltaTime = now - ctx.lastTime
+	ctx.fps = 1 / ctx.deltaTime
+	ctx.lastTime = now
 
-	img := GlTools.NewRenderObject(Type.Image)
-	img.UploadMesh(Mesh.Rect())
+	err := ctx.syncFonts()
+	ctx.renderer.Render(&ctx.drawList)
+	for _, loaded := range ctx.drawList.Fonts {
+		if cache, ok := loaded.font.(*ttf2atlas.GlyphCache); ok {
+			cache.NextFrame()
+		}
+	}
+	return err
+}
+func (ctx *Context) ClearAll() {
+	ctx.drawList.Clear()
+}
 
-	text := GlTools.NewRenderObject(Type.Text)
+// GetTime returns the seconds passed since the context was created.
+func (ctx *Context) GetTime() float32 {
+	return float32(time.Since(ctx.startTime).Seconds())
+}
+func (ctx *Context) GetDeltaTime() float32 {
+	return ctx.deltaTime
+}
+func (ctx *Context) GetFPS() float32 {
+	return ctx.fps
+}
+func (ctx *Context) LoadFont(path string, FontSize float32) (FontHandle, error) {
+	return ctx.LoadFontWithOptions(path, FontSize, ttf2atlas.DefaultOptions())
+}
 
-	outlineRect := GlTools.NewRenderObject(Type.OutlineRect)
-	outlineRect.UploadMesh(Mesh.Rect())
-	return Context{
-		lines:                   []Line{},
-		rects:                   []Rect{},
-		images:                  []Image{},
-		polygons:                []Polygon{},
-		circles:                 []Circle{},
-		mainProgram:             0,
-		lineRenderObject:        line,
-		rectRenderObject:        rect,
-		circleRenderObject:      circle,
-		polygonRenderObject:     polygon,
-		imageRenderObject:       img,
-		outlineRectRenderObject: outlineRect,
-		textRenderObject:        text,
-		window:                  window,
-		hwnd:                    hwnd,
-		loadedFonts:             map[uint32]atlasFont{},
-	}
-}
-func newWindow(name string) Window {
-	win, hwnd := initGlfw(name)
-	glfw.SwapInterval(0)
-	return Window{
-		name:       name,
-		glfwWindow: win,
-		hwnd:       hwnd,
-	}
-}
-
-func (ctx *Context) Init() {
-	vertShader, err := GlTools.CompileShader(Shader.RendererVertexShader, gl.VERTEX_SHADER)
+// LoadFontWithOptions loads the font at path, or returns the one loaded
+// before from the same file with the same FontSize and options that build
+// the same atlas.
+func (ctx *Context) LoadFontWithOptions(path string, FontSize float32, Opts ttf2atlas.Options) (FontHandle, error) {
+	key := fmt.Sprintf("%s %g %+v", pathKey(path), FontSize, Opts.Normalized())
+	if handle, ok := ctx.retainFont(key); ok {
+		return handle, nil
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return 0, &LoadError{Op: "load font", Path: path, Err: err}
+	}
+	return ctx.loadFont(path, key, data, FontSize, Opts)
+}
+
+// LoadImage loads the image at path, or returns the one loaded before from
+// the same file.
+func (ctx *Context) LoadImage(path string) (ImageHandle, int, int, error) {
+	key := pathKey(path)
+	if handle, img, ok := ctx.retainImage(key); ok {
+		return handle, img.width, img.height, nil
 	}
-	fragShader, err := GlTools.CompileShader(Shader.RendererFragmentShader, gl.FRAGMENT_SHADER)
+	file, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return 0, 0, 0, &LoadError{Op: "load image", Path: path, Err: err}
 	}
-	prog, err := GlTools.NewProgram(vertShader, fragShader)
+	defer file.Close()
+	return ctx.loadImage(path, key, file)
+}
 
+// loadImage decodes an image from Reader and uploads it. Path only names
+// the image and may be empty; Key shares it with later loads when not "".
+func (ctx *Context) loadImage(Path, Key string, Reader io.Reader) (ImageHandle, int, int, error) {
+	img, _, err := image.Decode(Reader)
+	if err != nil {
+		return 0, 0, 0, &LoadError{Op: "load image", Path: Path, Err: imageError(err)}
+	}
+	tex, width, height, err := ctx.renderer.UploadTexture(img)
 	if err != nil {
-		panic(err)
+		return 0, 0, 0, &LoadError{Op: "load image", Path: Path, Err: err}
 	}
-	ctx.mainProgram = prog
+	return ctx.addImage(assetName(Path), Key, tex, width, height), width, height, nil
+}
 
-	ctx.colorUniform = gl.GetUniformLocation(prog, gl.Str("BaseColor\x00"))
-	ctx.modelUniform = gl.GetUniformLocation(prog, gl.Str("Model\x00"))
-	ctx.cameraUniform = gl.GetUniformLocation(prog, gl.Str("Camera\x00"))
-	ctx.textureUniform = gl.GetUniformLocation(prog, gl.Str("tex\x00"))
-	ctx.textureEnabledUniform = gl.GetUniformLocation(prog, gl.Str("texEnabled\x00"))
-	ctx.vertexAttributeLocation = uint32(gl.GetAttribLocation(prog, gl.Str("Vert\x00")))
-	ctx.uvAttributeLocation = uint32(gl.GetAttribLocation(prog, gl.Str("Uv\x00")))
+// loadFont builds the atlas of the font file Data and uploads it. Path
+// names the font and may be empty; Key shares it with later loads when not
+// "".
+func (ctx *Context) loadFont(Path, Key string, Data []byte, FontSize float32, Opts ttf2atlas.Options) (FontHandle, error) {
+	var img *image.RGBA
+	var atlas *ttf2atlas.FontAtlas
+	var err error
+	if ctx.fontCache != "" {
+		name := "font"
+		if Path != "" {
+			base := path.Base(filepath.ToSlash(Path))
+			name = strings.TrimSuffix(base, path.Ext(base))
+		}
+		img, atlas, err = ttf2atlas.FontDataToAtlasCached(name, Data, FontSize, Opts, ctx.fontCache)
+	} else {
+		img, atlas, err = ttf2atlas.FontDataToAtlas(Data, FontSize, Opts)
+	}
+	if err != nil {
+		return 0, &LoadError{Op: "load font", Path: Path, Err: err}
+	}
+	loaded := newLoadedFont(atlas)
+	if err := loaded.upload(ctx.renderer, img); err != nil {
+		return 0, &LoadError{Op: "load font", Path: Path, Err: err}
+	}
+	return ctx.addFont(fmt.Sprintf("%s %gpx", assetName(Path), FontSize), Key, loaded), nil
+}
 
-	ctx.vertexAttribute = GlTools.NewAttribute(ctx.vertexAttributeLocation, 2, 0)
-	ctx.uvAttribute = GlTools.NewAttribute(ctx.uvAttributeLocation, 2, 2)
+// LoadBMFont loads a BMFont bitmap font and uploads all of its pages, or
+// returns the one loaded before from the same file.
+func (ctx *Context) LoadBMFont(path string) (FontHandle, error) {
+	key := "bmfont " + pathKey(path)
+	if handle, ok := ctx.retainFont(key); ok {
+		return handle, nil
+	}
+	font, pages, err := ttf2atlas.LoadBMFont(path)
+	return ctx.uploadBMFont(path, key, font, pages, err)
 }
 
-func (ctx *Context) Render() {
-	var time = float32(glfw.GetTime())
-	ctx.deltaTime = time - ctx.lastTime
-	ctx.fps = 1 / ctx.deltaTime
-	ctx.lastTime = time
-
-	w32.SetWindowPos(
-		ctx.hwnd,
-		w32.HWND_TOPMOST,
-		0, 0, 0, 0,
-		w32.SWP_NOMOVE|w32.SWP_NOSIZE|w32.SWP_SHOWWINDOW,
-	)
-
-	var modelMatrix mgl32.Mat4
-
-	width, height := ctx.window.GetSize()
-
-	ortho := mgl32.Ortho(0, float32(width), float32(height), 0, -10000, 10000) //mgl32.Ortho2D(0, float32(width), float32(height), 0)
-
-	gl.Viewport(0, 0, int32(width), int32(height))
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-	gl.ClearColor(0, 0, 0, 0)
-	gl.UseProgram(ctx.mainProgram)
-
-	ctx.lineRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-	for _, v := range ctx.lines {
-		ctx.lineRenderObject.UploadMesh(Mesh.Line(v.X1, v.Y1, v.X2, v.Y2))
-		if v.Fill {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		} else {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		}
-		modelMatrix = mgl32.Translate3D(v.TransformX, v.TransformY, float32(v.ZIndex))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform1i(ctx.textureEnabledUniform, 0)
-		ctx.lineRenderObject.Render()
-	}
-	ctx.lineRenderObject.End()
-
-	ctx.outlineRectRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-	for _, v := range ctx.outlineRects {
-		modelMatrix = mgl32.Translate3D(v.X, v.Y, float32(v.ZIndex)).Mul4(mgl32.Translate3D(v.TransformX, v.TransformY, 0)).Mul4(mgl32.HomogRotate3DZ(v.Rotation)).Mul4(mgl32.Translate3D(-v.AnchorPointX*v.Width, -v.AnchorPointY*v.Height, 0)).Mul4(mgl32.Scale3D(v.Width, v.Height, 1))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.Uniform1i(ctx.textureEnabledUniform, 0)
-		ctx.outlineRectRenderObject.Render()
-	}
-	ctx.outlineRectRenderObject.End()
-
-	ctx.rectRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-
-	for _, v := range ctx.rects {
-		if v.Fill {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		} else {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		}
-		modelMatrix = mgl32.Translate3D(v.X, v.Y, float32(v.ZIndex)).Mul4(mgl32.Translate3D(v.TransformX, v.TransformY, 0)).Mul4(mgl32.HomogRotate3DZ(v.Rotation)).Mul4(mgl32.Translate3D(-v.AnchorPointX*v.Width, -v.AnchorPointY*v.Height, 0)).Mul4(mgl32.Scale3D(v.Width, v.Height, 1))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.Uniform1i(ctx.textureEnabledUniform, 0)
+// uploadBMFont uploads the pages of a font from ttf2atlas.LoadBMFont or
+// LoadBMFontFS, which returned Err.
+func (ctx *Context) uploadBMFont(Path, Key string, Font *ttf2atlas.BitmapFont, Pages []*image.RGBA, Err error) (FontHandle, error) {
+	if Err != nil {
+		return 0, &LoadError{Op: "load bmfont", Path: Path, Err: Err}
+	}
+	loaded := newLoadedFont(Font)
+	images := make([]image.Image, len(Pages))
+	for i, page := range Pages {
+		images[i] = page
+	}
+	if err := loaded.uploadAll(ctx.renderer, images...); err != nil {
+		return 0, &LoadError{Op: "load bmfont", Path: Path, Err: err}
+	}
+	return ctx.addFont(assetName(Path), Key, loaded), nil
+}
 
-		ctx.rectRenderObject.Render()
+// LoadDynamicFont loads a font whose glyphs are rasterized the first time
+// they are drawn, so any rune of the font can be used. Loads of the same
+// file with the same FontSize and Opts share one font.
+func (ctx *Context) LoadDynamicFont(path string, FontSize float32, Opts ttf2atlas.CacheOptions) (FontHandle, error) {
+	key := fmt.Sprintf("dynamic %s %g %+v", pathKey(path), FontSize, Opts)
+	if handle, ok := ctx.retainFont(key); ok {
+		return handle, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, &LoadError{Op: "load dynamic font", Path: path, Err: err}
+	}
+	return ctx.loadDynamicFont(path, key, data, FontSize, Opts)
+}
 
+func (ctx *Context) loadDynamicFont(Path, Key string, Data []byte, FontSize float32, Opts ttf2atlas.CacheOptions) (FontHandle, error) {
+	cache, err := ttf2atlas.NewGlyphCacheFromData(Data, FontSize, Opts)
+	if err != nil {
+		return 0, &LoadError{Op: "load dynamic font", Path: Path, Err: err}
+	}
+	loaded := newLoadedFont(cache)
+	if err := loaded.upload(ctx.renderer, cache.Pages()[0]); err != nil {
+		cache.Close()
+		return 0, &LoadError{Op: "load dynamic font", Path: Path, Err: err}
 	}
-	ctx.rectRenderObject.End()
+	cache.TakeDirtyPages()
+	return ctx.addFont(fmt.Sprintf("%s %gpx dynamic", assetName(Path), FontSize), Key, loaded), nil
+}
 
-	ctx.circleRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-	for _, v := range ctx.circles {
-		if v.Fill {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		} else {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		}
-		modelMatrix = mgl32.Translate3D(v.X, v.Y, float32(v.ZIndex)).Mul4(mgl32.Translate3D(v.TransformX, v.TransformY, 0)).Mul4(mgl32.HomogRotate3DZ(v.Rotation)).Mul4(mgl32.Translate3D(-v.AnchorPointX*v.ScaleX, -v.AnchorPointY*v.ScaleY, 0)).Mul4(mgl32.Scale3D(v.ScaleX, v.ScaleY, 1))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.Uniform1i(ctx.textureEnabledUniform, 0)
-		ctx.circleRenderObject.Render()
-	}
-	ctx.circleRenderObject.End()
-	ctx.polygonRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-	for _, v := range ctx.polygons {
-		if v.Fill {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		} else {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		}
-		ctx.polygonRenderObject.UploadMesh(Mesh.Polygon(
-			v.X1, v.Y1,
-			v.X2, v.Y2,
-			v.X3, v.Y3,
-		))
-
-		modelMatrix = mgl32.Translate3D(v.TransformX, v.TransformY, float32(v.ZIndex)).Mul4(mgl32.HomogRotate3DZ(v.Rotation))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.Uniform1i(ctx.textureEnabledUniform, 0)
-		ctx.polygonRenderObject.Render()
-
-	}
-	ctx.polygonRenderObject.End()
-
-	ctx.imageRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-	for _, v := range ctx.images {
-		ctx.imageRenderObject.ChangeTexture(v.Image)
-		if v.Fill {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		} else {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		}
-		modelMatrix = mgl32.Translate3D(v.X, v.Y, float32(v.ZIndex)).Mul4(mgl32.Translate3D(v.TransformX, v.TransformY, 0)).Mul4(mgl32.HomogRotate3DZ(v.Rotation)).Mul4(mgl32.Translate3D(-v.AnchorPointX*v.Width, -v.AnchorPointY*v.Height, 0)).Mul4(mgl32.Scale3D(v.Width, v.Height, 1))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.Uniform1i(ctx.textureUniform, 0)
-		gl.Uniform1i(ctx.textureEnabledUniform, 1)
-
-		ctx.imageRenderObject.Render()
-	}
-	ctx.imageRenderObject.End()
-
-	ctx.textRenderObject.Begin()
-	ctx.vertexAttribute.Use()
-	ctx.uvAttribute.Use()
-	for _, v := range ctx.texts {
-		fontInfo, ok := ctx.loadedFonts[v.FontTexture]
+// NewFontFamily combines loaded fonts into one that falls back to the next
+// font for runes the previous ones lack. The family holds a reference to
+// each font until it is released itself. It returns 0 when a font is not
+// loaded.
+func (ctx *Context) NewFontFamily(Fonts ...FontHandle) FontHandle {
+	if len(Fonts) == 0 {
+		return 0
+	}
+	members := make([]*loadedFont, 0, len(Fonts))
+	fonts := make([]ttf2atlas.Font, 0, len(Fonts))
+	names := make([]string, 0, len(Fonts))
+	for _, handle := range Fonts {
+		loaded, ok := ctx.drawList.Fonts[handle]
 		if !ok {
-			continue
+			return 0
 		}
-		ctx.textRenderObject.UploadMesh(Mesh.Text(v.Text, fontInfo.Size.X, fontInfo.Size.Y, fontInfo.FontSize, v.Interval))
-		ctx.textRenderObject.ChangeTexture(v.FontTexture)
-		if v.Fill {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
-		} else {
-			gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
-		}
-
-		var MaxLength int
-		var MaxStrings int
-		strs := strings.Split(v.Text, "\n")
-		MaxStrings = len(strs)
-		for _, s := range strs {
-			if strLen := len(s); strLen > MaxLength {
-				MaxLength = strLen
-			}
-		}
-
-		modelMatrix = mgl32.Translate3D(v.X, v.Y, float32(v.ZIndex)).Mul4(mgl32.Translate3D(v.TransformX, v.TransformY, 0)).Mul4(mgl32.HomogRotate3DZ(v.Rotation)).Mul4(mgl32.Translate3D(-v.AnchorPointX*((v.Width+v.Size)*float32(MaxLength)*v.Interval), -v.AnchorPointY*((v.Height+v.Size)*float32(MaxStrings)), 0)).Mul4(mgl32.Scale3D(v.Width+v.Size, v.Height+v.Size, 1))
-		gl.UniformMatrix4fv(ctx.cameraUniform, 1, false, &ortho[0])
-		gl.UniformMatrix4fv(ctx.modelUniform, 1, false, &modelMatrix[0])
-		gl.Uniform4fv(ctx.colorUniform, 1, &v.Color[0])
-		gl.Uniform1i(ctx.textureUniform, 0)
-		gl.Uniform1i(ctx.textureEnabledUniform, 1)
-
-		ctx.textRenderObject.Render()
+		members = append(members, loaded)
+		fonts = append(fonts, loaded.font)
+		names = append(names, fmt.Sprint(handle))
 	}
-	ctx.textRenderObject.End()
-
-	gl.UseProgram(0)
-	glfw.PollEvents()
-	ctx.window.SwapBuffers()
-}
-func (ctx *Context) ClearAll() {
-	ctx.lines = []Line{}
-	ctx.rects = []Rect{}
-	ctx.images = []Image{}
-	ctx.polygons = []Polygon{}
-	ctx.circles = []Circle{}
-	ctx.outlineRects = []OutlineRect{}
-	ctx.texts = []Text{}
+	for _, member := range members {
+		member.refs++
+	}
+	family := newLoadedFont(ttf2atlas.NewFamily(fonts...))
+	family.members = members
+	return ctx.addFont("family of "+strings.Join(names, ", "), "", family)
 }
 
-func (ctx *Context) GetDeltaTime() float32 {
-	return ctx.deltaTime
+// assetName is what ListAssets calls an asset loaded from Path.
+func assetName(Path string) string {
+	if Path == "" {
+		return "(memory)"
+	}
+	return Path
 }
-func (ctx *Context) GetFPS() float32 {
-	return ctx.fps
+
+// prepareText looks up every glyph of Text while the frame is recorded, so
+// glyph caches rasterize what they miss before syncFonts uploads them, and
+// remembers the runes no font could draw.
+func (ctx *Context) prepareText(Font FontHandle, Text string) {
+	loaded, ok := ctx.drawList.Fonts[Font]
+	if !ok {
+		return
+	}
+	for _, r := range ttf2atlas.MissingRunes(loaded.font, Text) {
+		loaded.missing[r] = true
+	}
+	// Tab stops are measured in spaces whether Text has one or not.
+	ctx.prepareGlyphs(Font, " ")
 }
-func (ctx *Context) LoadFont(path string, FontSize float32) uint32 {
-	atlas, err := ttf2atlas.FontToAtlas(path, FontSize)
-	if err != nil {
-		return 0
+
+// prepareGlyphs looks up the glyphs of Runes like prepareText, for the ones
+// layout falls back on, without remembering those the font lacks.
+func (ctx *Context) prepareGlyphs(Font FontHandle, Runes string) {
+	loaded, ok := ctx.drawList.Fonts[Font]
+	if !ok {
+		return
 	}
-	tex := GlTools.MakeTexture(true)
-	w, h, _ := GlTools.UploadTextureFromImage(tex, atlas)
-	ctx.loadedFonts[tex] = atlasFont{
-		Size:     vec2{X: w, Y: h},
-		FontSize: FontSize,
+	for _, r := range Runes {
+		loaded.font.Glyph(r)
+	}
+}
+
+// syncFonts uploads the glyph cache pages that changed since the last frame.
+// When a page fails it and the pages after it stay dirty for the next frame,
+// so the textures of a font always follow the order of its pages.
+func (ctx *Context) syncFonts() error {
+	var errs []error
+	for _, loaded := range ctx.drawList.Fonts {
+		cache, ok := loaded.font.(*ttf2atlas.GlyphCache)
+		if !ok {
+			continue
+		}
+		pages := cache.Pages()
+		dirty := cache.TakeDirtyPages()
+		for i, page := range dirty {
+			var err error
+			if page < len(loaded.textures) {
+				err = ctx.renderer.UpdateTexture(loaded.textures[page], pages[page])
+			} else {
+				err = loaded.upload(ctx.renderer, pages[page])
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("upload page %d of font %s: %w", page, loaded.name, err))
+				for _, page := range dirty[i:] {
+					cache.MarkDirty(page)
+				}
+				break
+			}
+		}
 	}
-	return tex
+	return errors.Join(errs...)
 }
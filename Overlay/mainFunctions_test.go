@@ -0,0 +1,103 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"errors"
+	"image"
+	"path/filepath"
+	"testing"
+)
+
+var errUpload = errors.New("upload failed")
+
+// failingRenderer is a SoftwareRenderer whose texture uploads fail while
+// fail is set.
+type failingRenderer struct {
+	*SoftwareRenderer
+	fail    bool
+	updates int
+}
+
+func (r *failingRenderer) UploadTexture(Img image.Image) (uint32, int, int, error) {
+	if r.fail {
+		return 0, 0, 0, errUpload
+	}
+	return r.SoftwareRenderer.UploadTexture(Img)
+}
+func (r *failingRenderer) UpdateTexture(TextureId uint32, Img image.Image) error {
+	if r.fail {
+		return errUpload
+	}
+	r.updates++
+	return r.SoftwareRenderer.UpdateTexture(TextureId, Img)
+}
+
+func newTestApp(Renderer Renderer) App {
+	ctx := newContext(Renderer)
+	return App{
+		context: ctx,
+		window:  Window{name: "test"},
+		state:   newDrawState(ctx.drawList.layer("")),
+		isRun:   true,
+	}
+}
+
+func TestRenderReturnsUploadErrors(t *testing.T) {
+	renderer := &failingRenderer{SoftwareRenderer: NewSoftwareRenderer(64, 32)}
+	app := newTestApp(renderer)
+	defer app.Dispose()
+	font, err := app.LoadDynamicFont(filepath.Join("..", "Fonts", "Vcr.ttf"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderer.fail = true
+	app.DrawText(0, 0, 16, 0, 0, font, 1, "abc")
+	if err := app.Render(); !errors.Is(err, errUpload) {
+		t.Fatalf("Render = %v, want the upload error", err)
+	}
+
+	// The page that failed is uploaded by the next frame.
+	renderer.fail = false
+	if err := app.Render(); err != nil {
+		t.Fatalf("Render = %v", err)
+	}
+	if renderer.updates != 1 {
+		t.Errorf("%d pages updated, want 1", renderer.updates)
+	}
+	if err := app.Render(); err != nil || renderer.updates != 1 {
+		t.Errorf("Render = %v with %d updates, want nothing left to upload", err, renderer.updates)
+	}
+}
+
+func TestTextBoxPreparesFallbackGlyphs(t *testing.T) {
+	app := newTestApp(NewSoftwareRenderer(64, 32))
+	defer app.Dispose()
+	font, err := app.LoadDynamicFont(filepath.Join("..", "Fonts", "Vcr.ttf"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := app.context.drawList.Fonts[font].font.(*ttf2atlas.GlyphCache)
+
+	// The ellipsis of a truncated box and the space tabs are measured in are
+	// rasterized while the frame is recorded, not while it is rendered.
+	app.SetEllipsis(true)
+	app.DrawTextBox(0, 0, 40, 16, 16, font, "aabbccdd")
+	app.DrawText(0, 16, 16, 0, 0, font, 1, "a\tb")
+	misses := cache.Stats().Misses
+	if err := app.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cache.Stats(); stats.Misses != misses {
+		t.Errorf("%d glyphs rasterized while rendering", stats.Misses-misses)
+	}
+	if dirty := cache.TakeDirtyPages(); len(dirty) != 0 {
+		t.Errorf("pages %v left to upload after Render", dirty)
+	}
+
+	zIndex := app.zIndex
+	app.DrawTextBox(0, 0, 40, 16, 16, font, "")
+	if app.zIndex != zIndex {
+		t.Errorf("an empty box moved the ZIndex from %d to %d", zIndex, app.zIndex)
+	}
+}
@@ -0,0 +1,105 @@
+package Overlay
+
+import "DrawerGO/Overlay/Mesh"
+
+// TextBounds is the layout of a string as DrawText draws it at X, Y = 0, 0
+// with no anchor, rotation or transform: add the X and Y given to DrawText
+// to place it on screen.
+type TextBounds struct {
+	Width, Height float32
+	Lines         []LineBounds
+}
+
+// LineBounds is the box of one line, as wide as its glyphs advance and one
+// line height tall.
+type LineBounds struct {
+	X, Y, Width, Height float32
+	Glyphs              []GlyphBounds
+}
+
+// GlyphBounds is the cell of one glyph, from the pen position before it to
+// the one after it and as tall as its line. Index is the byte offset of Rune
+// in the string. Runes the font cannot draw have no cell.
+type GlyphBounds struct {
+	Rune                rune
+	Index               int
+	X, Y, Width, Height float32
+}
+
+// GlyphAt returns the glyph whose cell contains X, Y.
+func (bounds TextBounds) GlyphAt(X, Y float32) (GlyphBounds, bool) {
+	for _, line := range bounds.Lines {
+		if Y < line.Y || Y >= line.Y+line.Height {
+			continue
+		}
+		for _, glyph := range line.Glyphs {
+			if X >= glyph.X && X < glyph.X+glyph.Width {
+				return glyph, true
+			}
+		}
+	}
+	return GlyphBounds{}, false
+}
+
+// MeasureText returns the width and height text takes when drawn with
+// DrawText at Size with Width and Height 0 and Interval 1.
+func (app *App) MeasureText(Font FontHandle, Size float32, text string) (float32, float32) {
+	bounds := app.MeasureTextBounds(Font, Size, text)
+	return bounds.Width, bounds.Height
+}
+
+// MeasureTextBounds returns the line and glyph boxes of text drawn like
+// MeasureText measures it, for hit testing or sizing things around them.
+func (app *App) MeasureTextBounds(Font FontHandle, Size float32, text string) TextBounds {
+	loaded, ok := app.context.drawList.Fonts[Font]
+	if !ok || loaded.font.FontMetrics().FontSize == 0 {
+		return TextBounds{}
+	}
+	scale := Size / loaded.font.FontMetrics().FontSize
+	return newTextBounds(Mesh.Layout(text, loaded.font, 1, app.state.kerning, app.state.tabSize), scale)
+}
+
+// MeasureTextBox returns the lines and glyphs DrawTextBox would draw with
+// the same arguments, relative to the top left of the box.
+func (app *App) MeasureTextBox(Width, Height, Size float32, Font FontHandle, text string) TextBounds {
+	loaded, ok := app.context.drawList.Fonts[Font]
+	if !ok || loaded.font.FontMetrics().FontSize == 0 {
+		return TextBounds{}
+	}
+	scale := Size / loaded.font.FontMetrics().FontSize
+	box := TextBox{
+		Text:          Text{TabSize: app.state.tabSize},
+		BoxWidth:      Width,
+		BoxHeight:     Height,
+		Align:         app.state.textBox.align,
+		VerticalAlign: app.state.textBox.verticalAlign,
+		LineSpacing:   app.state.textBox.lineSpacing,
+		Ellipsis:      app.state.textBox.ellipsis,
+	}
+	return newTextBounds(box.box(scale).Layout(text, loaded.font, 1, app.state.kerning), scale)
+}
+
+// newTextBounds scales a layout in font pixels by Scale.
+func newTextBounds(Layout Mesh.TextLayout, Scale float32) TextBounds {
+	bounds := TextBounds{Width: Layout.Width * Scale, Height: Layout.Height * Scale}
+	for _, line := range Layout.Lines {
+		out := LineBounds{
+			X:      line.X * Scale,
+			Y:      line.Y * Scale,
+			Width:  line.Width * Scale,
+			Height: Layout.LineHeight * Scale,
+		}
+		for _, glyph := range line.Glyphs {
+			out.Glyphs = append(out.Glyphs, GlyphBounds{
+				Rune:   glyph.Rune,
+				Index:  glyph.Index,
+				X:      glyph.X * Scale,
+				Y:      out.Y,
+				Width:  glyph.Advance * Scale,
+				Height: out.Height,
+			})
+		}
+		bounds.Lines = append(bounds.Lines, out)
+	}
+	return bounds
+}
@@ -0,0 +1,30 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestMeasureTextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "measure_text", Width: 128, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			font, err := app.LoadFont(assets.FontPath, 16)
+			Golden.Must(t, err)
+			width, height := app.MeasureText(font, 16, "Label")
+			app.SetColor(0, 0, 128, 255)
+			app.DrawRect(64-width/2-2, 8-2, width+4, height+4)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(64-width/2, 8, 16, 0, 0, font, 1, "Label")
+
+			bounds := app.MeasureTextBounds(font, 16, "Hit")
+			if glyph, ok := bounds.GlyphAt(bounds.Width/2, 4); ok {
+				app.SetColor(128, 0, 0, 255)
+				app.DrawRect(4+glyph.X, 36+glyph.Y, glyph.Width, glyph.Height)
+			}
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(4, 36, 16, 0, 0, font, 1, "Hit")
+		},
+	})
+}
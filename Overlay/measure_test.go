@@ -0,0 +1,66 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/ttf2atlas"
+	"reflect"
+	"testing"
+)
+
+func TestMeasureTextBounds(t *testing.T) {
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+	font := FontHandle(99)
+	app.context.drawList.Fonts[font] = newLoadedFont(&ttf2atlas.BitmapFont{
+		Metrics: ttf2atlas.Metrics{FontSize: 10, Ascent: 8, Descent: 2},
+		Glyphs: map[rune]ttf2atlas.Glyph{
+			'a': {Width: 5, Height: 6, Advance: 6},
+			'b': {Width: 7, Height: 8, Advance: 8},
+		},
+	})
+
+	// At twice the font size; the font has no x, so it has no cell.
+	bounds := app.MeasureTextBounds(font, 20, "ab\nxa")
+	want := TextBounds{Width: 28, Height: 40, Lines: []LineBounds{
+		{X: 0, Y: 0, Width: 28, Height: 20, Glyphs: []GlyphBounds{
+			{Rune: 'a', Index: 0, X: 0, Y: 0, Width: 12, Height: 20},
+			{Rune: 'b', Index: 1, X: 12, Y: 0, Width: 16, Height: 20},
+		}},
+		{X: 0, Y: 20, Width: 12, Height: 20, Glyphs: []GlyphBounds{
+			{Rune: 'a', Index: 4, X: 0, Y: 20, Width: 12, Height: 20},
+		}},
+	}}
+	if !reflect.DeepEqual(bounds, want) {
+		t.Fatalf("bounds = %+v, want %+v", bounds, want)
+	}
+	if width, height := app.MeasureText(font, 20, "ab\nxa"); width != 28 || height != 40 {
+		t.Errorf("MeasureText = %gx%g, want 28x40", width, height)
+	}
+
+	tests := []struct {
+		x, y  float32
+		index int
+		ok    bool
+	}{
+		{0, 0, 0, true},
+		{11.9, 19.9, 0, true},
+		{12, 5, 1, true},
+		{27.9, 5, 1, true},
+		// Cells end before the next pen position and the next line.
+		{28, 5, 0, false},
+		{5, 20, 4, true},
+		{20, 25, 0, false},
+		{5, -1, 0, false},
+		{5, 40, 0, false},
+		{-1, 5, 0, false},
+	}
+	for _, test := range tests {
+		glyph, ok := bounds.GlyphAt(test.x, test.y)
+		if ok != test.ok || (ok && glyph.Index != test.index) {
+			t.Errorf("GlyphAt(%g, %g) = %+v %v, want byte %d %v", test.x, test.y, glyph, ok, test.index, test.ok)
+		}
+	}
+
+	if bounds := app.MeasureTextBounds(FontHandle(98), 20, "ab"); !reflect.DeepEqual(bounds, TextBounds{}) {
+		t.Errorf("bounds in a font that is not loaded = %+v", bounds)
+	}
+}
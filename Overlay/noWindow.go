@@ -0,0 +1,66 @@
+//go:build !windows
+
+package Overlay
+
+import "errors"
+
+// Without Windows there is no overlay window: New fails and the App can
+// only be made with NewHeadless, which draws with SoftwareRenderer.
+
+var errNoWindow = errors.New("the overlay window needs windows")
+
+// New opens the transparent overlay window; on this system it always
+// returns an *InitError, use NewHeadless instead.
+func New() (App, error) {
+	return App{}, &InitError{Op: "open window", Err: errNoWindow}
+}
+
+type Window struct {
+	name string
+}
+
+func (window *Window) isOpen() bool {
+	return false
+}
+func (window *Window) shouldClose() bool {
+	return true
+}
+func (window *Window) close() {}
+func (window *Window) cursorPos() (float32, float32) {
+	return 0, 0
+}
+func (window *Window) size() (int, int) {
+	return 0, 0
+}
+
+// GetGLVersion returns the OpenGL version string of the driver, or "" for a
+// headless App.
+func (app *App) GetGLVersion() string {
+	return ""
+}
+
+func (app *App) IsKeyDown(Key int) bool {
+	return false
+}
+func (app *App) IsMouseButton1Down() bool {
+	return false
+}
+func (app *App) IsMouseButton2Down() bool {
+	return false
+}
+func (app *App) IsShiftDown() bool {
+	return false
+}
+func (app *App) IsCtrlDown() bool {
+	return false
+}
+func (app *App) IsBackspaceDown() bool {
+	return false
+}
+func (app *App) IsEnterDown() bool {
+	return false
+}
+
+func GetDisplaySize() (int32, int32) {
+	return 0, 0
+}
@@ -1,23 +1,17 @@
 package Overlay
 
 import (
-	"DrawerGO/Overlay/GlTools"
-	"fmt"
-	"github.com/go-gl/gl/v4.6-core/gl"
-	"github.com/go-gl/glfw/v3.3/glfw"
-	"github.com/go-gl/mathgl/mgl32"
-	"github.com/gonutz/w32/v2"
+	"DrawerGO/Overlay/Engine"
+	"DrawerGO/Overlay/ttf2atlas"
+	"image"
+	"io"
+	"io/fs"
 	"runtime"
+	"sort"
 )
 
 // var loadedImages map[string][]byte
-var currentColor = [4]float32{1, 1, 1, 1}
 var defaultColor = [4]float32{1, 1, 1, 1}
-var currentRotation float32 = 0
-var currentPositionX, currentPositionY float32 = 0, 0
-var currentZIndex uint32 = 0
-var currentAnchorPointX, currentAnchorPointY float32 = 0, 0
-var currentFill = true
 
 type ProgressBarDirection byte
 
@@ -29,17 +23,35 @@ const (
 	PROGRESS_BAR_DIRECTION_CENTER
 )
 
+type TextAlign byte
+
+const (
+	TEXT_ALIGN_LEFT TextAlign = iota
+	TEXT_ALIGN_CENTER
+	TEXT_ALIGN_RIGHT
+	TEXT_ALIGN_JUSTIFY
+)
+
+type TextVerticalAlign byte
+
+const (
+	TEXT_ALIGN_TOP TextVerticalAlign = iota
+	TEXT_ALIGN_MIDDLE
+	TEXT_ALIGN_BOTTOM
+)
+
 //var thickness float32 = 1
 
-func New() App {
-	window := newWindow("DrawerOverlayWindow")
-	initGl()
-	ctx := newContext(window.glfwWindow, window.hwnd)
+// NewHeadless creates an App without a window or GL context. Every Render
+// rasterizes the frame with SoftwareRenderer into a Width x Height image that
+// can be read back with Frame.
+func NewHeadless(Width, Height int) App {
+	ctx := newContext(NewSoftwareRenderer(Width, Height))
 
-	ctx.Init()
 	return App{
 		context: ctx,
-		window:  window,
+		window:  Window{name: "DrawerOverlayHeadless"},
+		state:   newDrawState(ctx.drawList.layer("")),
 		isRun:   true,
 	}
 }
@@ -52,31 +64,74 @@ func (app *App) Stop() {
 	app.isRun = false
 }
 func (app *App) IsOpen() bool {
-	return !app.window.glfwWindow.ShouldClose() && app.isRun
+	if app.IsHeadless() {
+		return app.isRun
+	}
+	return !app.window.shouldClose() && app.isRun
 }
+
+// IsHeadless reports whether the App was created with NewHeadless.
+func (app *App) IsHeadless() bool {
+	return !app.window.isOpen()
+}
+
 func (app *App) Dispose() {
 	app.isRun = false
 	app.context.ClearAll()
-	glfw.Terminate()
+	app.context.unloadAll()
+	app.context.renderer.Dispose()
+	if !app.IsHeadless() {
+		app.window.close()
+	}
 }
-func (app *App) Render() {
-	app.context.Render()
+
+// Render draws the frame and starts the next one: the draw calls are
+// cleared, unmatched Pushes dropped and the color, transform, rotation and
+// anchor point reset. The other settings, such as the fill mode, layer,
+// kerning and text settings, stay until they are changed, layers keep their
+// settings and loaded assets stay loaded.
+//
+// The error is from uploading the glyphs dynamic fonts rasterized during
+// the frame. The frame is drawn anyway, and the upload is tried again by
+// the next Render.
+func (app *App) Render() error {
+	err := app.context.Render()
 	app.context.ClearAll()
-	app.ResetRotate()
+	app.stack = app.stack[:0]
 	app.ResetColor()
+	app.ResetTransform()
+	app.ResetRotate()
 	app.ResetAnchorPoint()
-	currentZIndex = 0
+	app.zIndex = 0
+	return err
+}
+
+// Frame returns a copy of the last frame drawn by a headless App, or nil
+// when the App renders into a window.
+func (app *App) Frame() image.Image {
+	software, ok := app.context.renderer.(*SoftwareRenderer)
+	if !ok {
+		return nil
+	}
+	src := software.Image()
+	frame := image.NewRGBA(src.Bounds())
+	copy(frame.Pix, src.Pix)
+	return frame
 }
 
 func (app *App) GetMousePosition() (float32, float32) {
-	x, y := app.window.glfwWindow.GetCursorPos()
-	return float32(x), float32(y)
+	if app.IsHeadless() {
+		return 0, 0
+	}
+	return app.window.cursorPos()
 }
 
 func (app *App) GetMonitorSize() (int, int) {
-	x, y := app.window.glfwWindow.GetSize()
-	y -= 1
-	return x, y
+	if software, ok := app.context.renderer.(*SoftwareRenderer); ok {
+		size := software.Image().Rect.Size()
+		return size.X, size.Y
+	}
+	return app.window.size()
 }
 
 /*
@@ -85,8 +140,10 @@ Renderer
 
 */
 
+// SetColor sets the color of the following draw calls until the next
+// Render.
 func (app *App) SetColor(R, G, B, A byte) {
-	currentColor = [4]float32{
+	app.state.color = [4]float32{
 		float32(R) / 255.0,
 		float32(G) / 255.0,
 		float32(B) / 255.0,
@@ -95,112 +152,174 @@ func (app *App) SetColor(R, G, B, A byte) {
 
 }
 func (app *App) ResetColor() {
-	currentColor = defaultColor
+	app.state.color = defaultColor
 }
 func (app *App) ResetAnchorPoint() {
-	currentAnchorPointX = 0
-	currentAnchorPointY = 0
+	app.state.anchorPointX = 0
+	app.state.anchorPointY = 0
 }
 func (app *App) DrawLine(X1, Y1, X2, Y2 float32) {
-	currentZIndex++
-	app.context.lines = append(app.context.lines, Line{
+	app.zIndex++
+	app.state.layer.add(Line{
 		X1: X1,
 		Y1: Y1,
 
 		X2:           X2,
 		Y2:           Y2,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 }
 func (app *App) DrawOutlineRect(X, Y, Width, Height float32) {
-	currentZIndex++
-	app.context.outlineRects = append(app.context.outlineRects, OutlineRect{
+	app.zIndex++
+	app.state.layer.add(OutlineRect{
 		X: X,
 		Y: Y,
 
 		Width:        Width,
 		Height:       Height,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
 	})
 }
 func (app *App) DrawRect(X, Y, Width, Height float32) {
-	currentZIndex++
-	app.context.rects = append(app.context.rects, Rect{
+	app.zIndex++
+	app.state.layer.add(Rect{
 		X: X,
 		Y: Y,
 
 		Width:        Width,
 		Height:       Height,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 }
 
-// DrawImage To get the ImageId, you need to upload an image via LoadImage
-func (app *App) DrawImage(X, Y, Width, Height float32, ImageId uint32) {
-	currentZIndex++
-	app.context.images = append(app.context.images, Image{
+// DrawImage draws an image from LoadImage stretched to Width x Height.
+// Handles that were released draw nothing.
+func (app *App) DrawImage(X, Y, Width, Height float32, Img ImageHandle) {
+	img, ok := app.context.images[Img]
+	if !ok {
+		return
+	}
+	app.zIndex++
+	app.state.layer.add(Image{
 		X:            X,
 		Y:            Y,
 		Width:        Width,
 		Height:       Height,
-		Image:        ImageId,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Image:        img.texture,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 }
-func (app *App) DrawText(X, Y, Size, Width, Height float32, Font uint32, Interval float32, text string) {
+
+// DrawText draws text with a font from LoadFont at Size pixels, stretched by
+// Width and Height pixels. Interval scales the advance of every glyph, 1 keeps
+// the spacing of the font.
+func (app *App) DrawText(X, Y, Size, Width, Height float32, Font FontHandle, Interval float32, text string) {
 	if len(text) == 0 {
 		return
 	}
-	currentZIndex++
-	app.context.texts = append(app.context.texts, Text{
+	app.context.prepareText(Font, text)
+	app.zIndex++
+	app.state.layer.add(Text{
 		X:            X,
 		Y:            Y,
 		Size:         Size,
 		Text:         text,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 		Width:        Width,
 		Height:       Height,
-		FontTexture:  Font,
+		Font:         Font,
 		Interval:     Interval,
+		Kerning:      app.state.kerning,
+		TabSize:      app.state.tabSize,
+
+		OutlineWidth:   app.state.textEffects.outlineWidth,
+		OutlineColor:   app.state.textEffects.outlineColor,
+		ShadowOffsetX:  app.state.textEffects.shadowOffsetX,
+		ShadowOffsetY:  app.state.textEffects.shadowOffsetY,
+		ShadowSoftness: app.state.textEffects.shadowSoftness,
+		ShadowColor:    app.state.textEffects.shadowColor,
+	})
+}
+
+// DrawTextBox draws text at Size pixels in the Width x Height box at X, Y,
+// wrapped at spaces to the width of the box and aligned by SetTextAlign.
+// The anchor point and rotation apply to the box. A Width of 0 does not wrap
+// and a Height of 0 lets the text run down as far as it needs.
+func (app *App) DrawTextBox(X, Y, Width, Height, Size float32, Font FontHandle, text string) {
+	if len(text) == 0 {
+		return
+	}
+	app.context.prepareText(Font, text)
+	if app.state.textBox.ellipsis && Height > 0 {
+		// The ellipsis, or the three dots that stand in for it.
+		app.context.prepareGlyphs(Font, "….")
+	}
+	app.zIndex++
+	app.state.layer.add(TextBox{
+		Text: Text{
+			X:            X,
+			Y:            Y,
+			Size:         Size,
+			Text:         text,
+			Color:        app.state.color,
+			Rotation:     app.state.rotation,
+			Transform:    app.state.transform,
+			ZIndex:       app.zIndex,
+			AnchorPointX: app.state.anchorPointX,
+			AnchorPointY: app.state.anchorPointY,
+			Fill:         app.state.fill,
+			Font:         Font,
+			Interval:     1,
+			Kerning:      app.state.kerning,
+			TabSize:      app.state.tabSize,
+
+			OutlineWidth:   app.state.textEffects.outlineWidth,
+			OutlineColor:   app.state.textEffects.outlineColor,
+			ShadowOffsetX:  app.state.textEffects.shadowOffsetX,
+			ShadowOffsetY:  app.state.textEffects.shadowOffsetY,
+			ShadowSoftness: app.state.textEffects.shadowSoftness,
+			ShadowColor:    app.state.textEffects.shadowColor,
+		},
+		BoxWidth:      Width,
+		BoxHeight:     Height,
+		Align:         app.state.textBox.align,
+		VerticalAlign: app.state.textBox.verticalAlign,
+		LineSpacing:   app.state.textBox.lineSpacing,
+		Ellipsis:      app.state.textBox.ellipsis,
 	})
 }
 func (app *App) DrawPolygon(X1, Y1, X2, Y2, X3, Y3 float32) {
-	currentZIndex++
-	app.context.polygons = append(app.context.polygons, Polygon{
+	app.zIndex++
+	app.state.layer.add(Polygon{
 		X1: X1,
 		Y1: Y1,
 
@@ -209,32 +328,30 @@ func (app *App) DrawPolygon(X1, Y1, X2, Y2, X3, Y3 float32) {
 
 		X3:           X3,
 		Y3:           Y3,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 }
 
 func (app *App) DrawCircle(X, Y, ScaleX, ScaleY float32) {
-	currentZIndex++
-	app.context.circles = append(app.context.circles, Circle{
+	app.zIndex++
+	app.state.layer.add(Circle{
 		X:            X,
 		Y:            Y,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
+		Transform:    app.state.transform,
 		ScaleX:       ScaleX,
 		ScaleY:       ScaleY,
-		Color:        currentColor,
-		Rotation:     currentRotation,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        app.state.color,
+		Rotation:     app.state.rotation,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 }
 func clamp[T float32 | float64 | int | int32 | int64 | int8 | int16](value, min, max T) T {
@@ -254,26 +371,26 @@ func (app *App) DrawProgressBar(X, Y, Width, Height, Value, Max float32, Directi
 	case PROGRESS_BAR_DIRECTION_RIGHT:
 		{
 			_w = clamp[float32](Value/Max, 0, 1)
-			x = Width * (currentAnchorPointX - _w/2)
-			y = Height * (currentAnchorPointY - _h/2)
+			x = Width * (app.state.anchorPointX - _w/2)
+			y = Height * (app.state.anchorPointY - _h/2)
 		}
 	case PROGRESS_BAR_DIRECTION_LEFT:
 		{
 			_w = -clamp[float32](Value/Max, 0, 1)
-			x = Width * (-currentAnchorPointX - _w/2)
-			y = Height * (currentAnchorPointY - _h/2)
+			x = Width * (-app.state.anchorPointX - _w/2)
+			y = Height * (app.state.anchorPointY - _h/2)
 		}
 	case PROGRESS_BAR_DIRECTION_TOP:
 		{
 			_h = clamp[float32](Value/Max, 0, 1)
-			x = Width * (currentAnchorPointX - _w/2)
-			y = Height * (-currentAnchorPointY + _h/2)
+			x = Width * (app.state.anchorPointX - _w/2)
+			y = Height * (-app.state.anchorPointY + _h/2)
 		}
 	case PROGRESS_BAR_DIRECTION_BOTTOM:
 		{
 			_h = -clamp[float32](Value/Max, 0, 1)
-			x = Width * (currentAnchorPointX - _w/2)
-			y = Height * (currentAnchorPointY - _h/2)
+			x = Width * (app.state.anchorPointX - _w/2)
+			y = Height * (app.state.anchorPointY - _h/2)
 		}
 	case PROGRESS_BAR_DIRECTION_CENTER:
 		{
@@ -283,49 +400,119 @@ func (app *App) DrawProgressBar(X, Y, Width, Height, Value, Max float32, Directi
 	if Direction == PROGRESS_BAR_DIRECTION_RIGHT {
 
 	}
-	currentZIndex++
-	app.context.rects = append(app.context.rects, Rect{
+	app.zIndex++
+	app.state.layer.add(Rect{
 		X: X,
 		Y: Y,
 
 		Width:        Width,
 		Height:       Height,
-		Color:        [4]float32{currentColor[0] / 2, currentColor[1] / 2, currentColor[2] / 2, currentColor[3] / 2},
-		Rotation:     0,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        [4]float32{app.state.color[0] / 2, app.state.color[1] / 2, app.state.color[2] / 2, app.state.color[3] / 2},
+		Rotation:     app.state.rotation,
+		Transform:    app.state.transform,
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 
-	currentZIndex++
-	app.context.rects = append(app.context.rects, Rect{
+	// The fill turns with the background around the pivot of the background,
+	// not around its own.
+	pivot := Engine.Identity().Translate(X, Y).Rotate(app.state.rotation).Translate(-X, -Y)
+	app.zIndex++
+	app.state.layer.add(Rect{
 		X: X - x,
 		Y: Y - y,
 
 		Width:        _w * Width,
 		Height:       _h * Height,
-		Color:        currentColor,
-		Rotation:     0,
-		TransformX:   currentPositionX,
-		TransformY:   currentPositionY,
-		ZIndex:       currentZIndex,
-		AnchorPointX: currentAnchorPointX,
-		AnchorPointY: currentAnchorPointY,
-		Fill:         currentFill,
+		Color:        app.state.color,
+		Transform:    app.state.transform.Multiply(pivot),
+		ZIndex:       app.zIndex,
+		AnchorPointX: app.state.anchorPointX,
+		AnchorPointY: app.state.anchorPointY,
+		Fill:         app.state.fill,
 	})
 }
-func (app *App) RotateByRad(Deg float32) {
-	currentRotation = mgl32.DegToRad(Deg)
+
+// SetRotation rotates the following primitives by Angle around their anchor
+// point. Lines and polygons pivot around the anchor point of their bounding
+// box. Render resets it.
+func (app *App) SetRotation(Angle Engine.Angle) {
+	app.state.rotation = Angle
+}
+func (app *App) GetRotation() Engine.Angle {
+	return app.state.rotation
+}
+func (app *App) RotateByRad(Rad float32) {
+	app.SetRotation(Engine.Rad(Rad))
 }
 func (app *App) RotateByDeg(Deg float32) {
-	currentRotation = Deg
+	app.SetRotation(Engine.Deg(Deg))
 }
+
+// Translate moves the origin of the following draw calls by X, Y in the
+// current transform, so nested calls add up. Like every change to the
+// transform it lasts until the next Render.
 func (app *App) Translate(X, Y float32) {
-	currentPositionX = X
-	currentPositionY = Y
+	app.state.transform = app.state.transform.Translate(X, Y)
+}
+
+// Scale scales the following draw calls around the current origin.
+func (app *App) Scale(X, Y float32) {
+	app.state.transform = app.state.transform.Scale(X, Y)
+}
+
+// Rotate rotates the coordinate system of the following draw calls around
+// the current origin. Unlike SetRotation it accumulates with Translate and
+// Scale.
+func (app *App) Rotate(Angle Engine.Angle) {
+	app.state.transform = app.state.transform.Rotate(Angle)
+}
+
+// Skew shears the following draw calls by the angles X and Y.
+func (app *App) Skew(X, Y Engine.Angle) {
+	app.state.transform = app.state.transform.Skew(X, Y)
+}
+
+// ApplyTransform multiplies Transform into the current transform, as if
+// its operations were called one by one.
+func (app *App) ApplyTransform(Transform Engine.Transform) {
+	app.state.transform = app.state.transform.Multiply(Transform)
+}
+func (app *App) SetTransform(Transform Engine.Transform) {
+	app.state.transform = Transform
+}
+func (app *App) GetTransform() Engine.Transform {
+	return app.state.transform
+}
+func (app *App) ResetTransform() {
+	app.state.transform = Engine.Identity()
+}
+
+// Push saves the whole drawing state: color, transform, rotation, anchor
+// point, fill mode, layer, kerning, text outline and shadow, tab size and
+// the text box alignment, line spacing and ellipsis.
+func (app *App) Push() {
+	app.stack = append(app.stack, app.state)
+}
+
+// Pop restores the state saved by the matching Push. It does nothing when
+// nothing was pushed.
+func (app *App) Pop() {
+	if len(app.stack) == 0 {
+		return
+	}
+	app.state = app.stack[len(app.stack)-1]
+	app.stack = app.stack[:len(app.stack)-1]
+}
+
+// With runs Draw between Push and Pop, so whatever Draw changes is undone
+// afterwards.
+func (app *App) With(Draw func()) {
+	app.Push()
+	defer app.Pop()
+	Draw()
 }
 func (app *App) GetFps() float32 {
 	return app.context.GetFPS()
@@ -334,59 +521,330 @@ func (app *App) GetDeltaTime() float32 {
 	return app.context.GetDeltaTime()
 }
 
-func (app *App) LoadImage(path string) (uint32, int, int) {
-	tex := GlTools.MakeTexture(true)
-	width, height, err := GlTools.UploadTexture(tex, path)
+// LoadImage loads a PNG or JPEG image and returns it with its size. The
+// error is a *LoadError.
+//
+// Every load has to be given back with ReleaseImage, or ReleaseFont for the
+// font loaders. Loading the same path again returns the same handle and
+// counts one more reference, so the texture is only freed when the last
+// load is released; loads from memory, readers and fs.FS are never shared.
+// Dispose frees everything still loaded.
+func (app *App) LoadImage(path string) (ImageHandle, int, int, error) {
+	return app.context.LoadImage(path)
+}
+
+// LoadImageFrom loads a PNG or JPEG image from Reader.
+func (app *App) LoadImageFrom(Reader io.Reader) (ImageHandle, int, int, error) {
+	return app.context.loadImage("", "", Reader)
+}
+
+// LoadImageFS loads a PNG or JPEG image from FS, for example an embed.FS.
+func (app *App) LoadImageFS(FS fs.FS, path string) (ImageHandle, int, int, error) {
+	file, err := FS.Open(path)
 	if err != nil {
-		fmt.Println(err)
-		gl.DeleteTextures(1, &tex)
-		return 0, 0, 0
+		return 0, 0, 0, &LoadError{Op: "load image", Path: path, Err: err}
 	}
-	return tex, width, height
+	defer file.Close()
+	return app.context.loadImage(path, "", file)
 }
-func (app *App) LoadFont(path string, FontSize float32) uint32 {
+
+// LoadFont loads a TrueType font rasterized at FontSize. Like every font
+// loader its error is a *LoadError, which errors.Is matches against
+// ErrNotFound, ErrDecode and ErrUnsupportedFormat.
+func (app *App) LoadFont(path string, FontSize float32) (FontHandle, error) {
 	return app.context.LoadFont(path, FontSize)
 }
-func (app *App) DeleteImage(imgId uint32) {
-	gl.DeleteTextures(1, &imgId)
+
+// LoadFontWithOptions loads a font with the glyphs Opts asks for, for example
+//
+//	app.LoadFontWithOptions("Fonts/Vcr.ttf", 32, ttf2atlas.Options{
+//		Ranges:  []ttf2atlas.Range{ttf2atlas.BasicLatin, ttf2atlas.Arrows},
+//		Charset: "°±",
+//	})
+//
+// With Opts.SDF set the font stays sharp at any DrawText size and can be
+// drawn with SetTextOutline and SetTextShadow.
+func (app *App) LoadFontWithOptions(path string, FontSize float32, Opts ttf2atlas.Options) (FontHandle, error) {
+	return app.context.LoadFontWithOptions(path, FontSize, Opts)
 }
-func (app *App) AnchorPoint(X, Y float32) {
-	currentAnchorPointX = X
-	currentAnchorPointY = Y
+
+// LoadFontFrom loads a font from the contents of its file, for example one
+// embedded with go:embed.
+func (app *App) LoadFontFrom(Data []byte, FontSize float32) (FontHandle, error) {
+	return app.context.loadFont("", "", Data, FontSize, ttf2atlas.DefaultOptions())
 }
-func (app *App) GetTime() float32 {
-	return float32(glfw.GetTime())
+
+// LoadFontFromWithOptions is LoadFontWithOptions for a font file already in
+// memory.
+func (app *App) LoadFontFromWithOptions(Data []byte, FontSize float32, Opts ttf2atlas.Options) (FontHandle, error) {
+	return app.context.loadFont("", "", Data, FontSize, Opts)
 }
-func (app *App) ResetRotate() {
-	currentRotation = 0
+
+// LoadFontFS loads a font from FS, for example an embed.FS.
+func (app *App) LoadFontFS(FS fs.FS, path string, FontSize float32) (FontHandle, error) {
+	return app.LoadFontFSWithOptions(FS, path, FontSize, ttf2atlas.DefaultOptions())
 }
-func (app *App) IsKeyDown(Key int) bool {
-	return w32.GetKeyState(int(Key)) > 1
+
+// LoadFontFSWithOptions is LoadFontWithOptions reading the font from FS.
+func (app *App) LoadFontFSWithOptions(FS fs.FS, path string, FontSize float32, Opts ttf2atlas.Options) (FontHandle, error) {
+	data, err := fs.ReadFile(FS, path)
+	if err != nil {
+		return 0, &LoadError{Op: "load font", Path: path, Err: err}
+	}
+	return app.context.loadFont(path, "", data, FontSize, Opts)
 }
-func (app *App) IsMouseButton1Down() bool {
-	return w32.GetAsyncKeyState(w32.VK_LBUTTON) > 1
+
+// LoadBMFont loads a bitmap font made with AngelCode BMFont or a tool that
+// writes the same .fnt files, in the text or the XML format. The page
+// images are looked up next to the .fnt file. The font is drawn at the size
+// it was made at by giving DrawText that size. Pages are sampled with
+// bilinear filtering like every texture, so glyphs are only sharp at that
+// size on whole pixel positions.
+func (app *App) LoadBMFont(path string) (FontHandle, error) {
+	return app.context.LoadBMFont(path)
 }
-func (app *App) IsMouseButton2Down() bool {
-	return w32.GetAsyncKeyState(w32.VK_RBUTTON) > 1
+
+// LoadBMFontFS loads a bitmap font and its pages from FS. There is no
+// variant for a single Reader, as the font spans several files.
+func (app *App) LoadBMFontFS(FS fs.FS, path string) (FontHandle, error) {
+	font, pages, err := ttf2atlas.LoadBMFontFS(FS, path)
+	return app.context.uploadBMFont(path, "", font, pages, err)
 }
-func (app *App) IsShiftDown() bool {
-	return w32.GetAsyncKeyState(w32.VK_SHIFT) > 1
+
+// LoadDynamicFont loads a font for text that is not known up front, like
+// player names or chat: glyphs are rasterized when DrawText first needs them
+// and the least recently used ones are evicted when the pages are full.
+func (app *App) LoadDynamicFont(path string, FontSize float32) (FontHandle, error) {
+	return app.context.LoadDynamicFont(path, FontSize, ttf2atlas.DefaultCacheOptions())
 }
-func (app *App) IsCtrlDown() bool {
-	return w32.GetAsyncKeyState(w32.VK_CONTROL) > 1
+
+// LoadDynamicFontWithOptions loads a dynamic font whose pages Opts sizes
+// and limits.
+func (app *App) LoadDynamicFontWithOptions(path string, FontSize float32, Opts ttf2atlas.CacheOptions) (FontHandle, error) {
+	return app.context.LoadDynamicFont(path, FontSize, Opts)
+}
+
+// LoadDynamicFontFrom is LoadDynamicFont for a font file already in memory.
+// The cache keeps using Data, so it must not be changed afterwards.
+func (app *App) LoadDynamicFontFrom(Data []byte, FontSize float32) (FontHandle, error) {
+	return app.context.loadDynamicFont("", "", Data, FontSize, ttf2atlas.DefaultCacheOptions())
+}
+
+// LoadDynamicFontFromWithOptions is LoadDynamicFontWithOptions for a font
+// file already in memory.
+func (app *App) LoadDynamicFontFromWithOptions(Data []byte, FontSize float32, Opts ttf2atlas.CacheOptions) (FontHandle, error) {
+	return app.context.loadDynamicFont("", "", Data, FontSize, Opts)
 }
-func (app *App) IsBackspaceDown() bool {
-	return w32.GetAsyncKeyState(w32.VK_BACK) > 1
+
+// LoadDynamicFontFS is LoadDynamicFont reading the font from FS, for example
+// an embed.FS.
+func (app *App) LoadDynamicFontFS(FS fs.FS, path string, FontSize float32) (FontHandle, error) {
+	return app.LoadDynamicFontFSWithOptions(FS, path, FontSize, ttf2atlas.DefaultCacheOptions())
 }
-func (app *App) IsEnterDown() bool {
-	return w32.GetAsyncKeyState(w32.VK_RETURN) > 1
+
+// LoadDynamicFontFSWithOptions is LoadDynamicFontWithOptions reading the
+// font from FS.
+func (app *App) LoadDynamicFontFSWithOptions(FS fs.FS, path string, FontSize float32, Opts ttf2atlas.CacheOptions) (FontHandle, error) {
+	data, err := fs.ReadFile(FS, path)
+	if err != nil {
+		return 0, &LoadError{Op: "load dynamic font", Path: path, Err: err}
+	}
+	return app.context.loadDynamicFont(path, "", data, FontSize, Opts)
 }
+
+// SetFontCacheDir makes LoadFont and its variants keep the atlases
+// they build in Dir and load them from there on the next launch instead of
+// rasterizing the font again. An empty Dir turns the cache off.
+func (app *App) SetFontCacheDir(Dir string) {
+	app.context.fontCache = Dir
+}
+
+// NewFontFamily returns a font that draws every rune with the first of Fonts
+// that has it, for example Vcr.ttf with a CJK font behind it:
+//
+//	font := app.NewFontFamily(vcr, cjk)
+//
+// The first font sets the size and line height; the others are scaled to
+// it. It returns 0 when one of Fonts is not loaded.
+func (app *App) NewFontFamily(Fonts ...FontHandle) FontHandle {
+	return app.context.NewFontFamily(Fonts...)
+}
+
+// MissingRunes returns the runes of Text that no font of Font can draw,
+// without drawing anything.
+func (app *App) MissingRunes(Font FontHandle, Text string) []rune {
+	loaded, ok := app.context.drawList.Fonts[Font]
+	if !ok {
+		return nil
+	}
+	return ttf2atlas.MissingRunes(loaded.font, Text)
+}
+
+// GetMissingRunes returns the runes DrawText was asked to draw with Font
+// since it was loaded that no font of it could draw, sorted.
+func (app *App) GetMissingRunes(Font FontHandle) []rune {
+	loaded, ok := app.context.drawList.Fonts[Font]
+	if !ok {
+		return nil
+	}
+	runes := make([]rune, 0, len(loaded.missing))
+	for r := range loaded.missing {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool {
+		return runes[i] < runes[j]
+	})
+	return runes
+}
+
+// GetFontStats returns the cache stats of a font from LoadDynamicFont, or
+// false for any other font.
+func (app *App) GetFontStats(Font FontHandle) (ttf2atlas.CacheStats, bool) {
+	loaded, ok := app.context.drawList.Fonts[Font]
+	if !ok {
+		return ttf2atlas.CacheStats{}, false
+	}
+	cache, ok := loaded.font.(*ttf2atlas.GlyphCache)
+	if !ok {
+		return ttf2atlas.CacheStats{}, false
+	}
+	return cache.Stats(), true
+}
+
+// ReleaseImage gives back one load of Image and frees it with the last.
+func (app *App) ReleaseImage(Image ImageHandle) {
+	app.context.releaseImage(Image)
+}
+
+// DeleteImage is ReleaseImage.
+func (app *App) DeleteImage(Image ImageHandle) {
+	app.ReleaseImage(Image)
+}
+
+// ReleaseFont gives back one load of Font and frees it with the last, along
+// with the names RegisterFont gave it. A family releases its fonts when it
+// is freed.
+func (app *App) ReleaseFont(Font FontHandle) {
+	app.context.releaseFont(Font)
+}
+
+// ListAssets returns every image and font still loaded with the memory it
+// takes, for finding leaks:
+//
+//	for _, asset := range app.ListAssets() {
+//		log.Println(asset)
+//	}
+func (app *App) ListAssets() []AssetInfo {
+	return app.context.listAssets()
+}
+
+// AnchorPoint sets the point of the following primitives that X, Y place
+// and that they rotate around, from 0, 0 at the top left to 1, 1 at the
+// bottom right. Render resets it to 0, 0.
+func (app *App) AnchorPoint(X, Y float32) {
+	app.state.anchorPointX = X
+	app.state.anchorPointY = Y
+}
+func (app *App) GetTime() float32 {
+	return app.context.GetTime()
+}
+func (app *App) ResetRotate() {
+	app.state.rotation = 0
+}
+
+// SetFillMode fills the following primitives, which is the default. Unlike
+// the color it stays set across frames, like SetWireframeMode and SetMode.
 func (app *App) SetFillMode() {
-	currentFill = true
+	app.state.fill = true
 }
 func (app *App) SetWireframeMode() {
-	currentFill = false
+	app.state.fill = false
 }
 func (app *App) SetMode(fill bool) {
-	currentFill = fill
+	app.state.fill = fill
+}
+
+// SetKerning turns kerning on or off for the following DrawText calls. It is
+// on by default and stays as set across frames.
+func (app *App) SetKerning(Enabled bool) {
+	app.state.kerning = Enabled
+}
+func (app *App) GetKerning() bool {
+	return app.state.kerning
+}
+
+// SetTextOutline draws the following text with an outline Width pixels wide
+// around it, in this frame and the next ones. Only fonts loaded with an SDF
+// atlas have outlines, and an outline cannot reach further than the Spread
+// of that atlas. A Width of 0 turns the outline off.
+func (app *App) SetTextOutline(Width float32, R, G, B, A byte) {
+	app.state.textEffects.outlineWidth = Width
+	app.state.textEffects.outlineColor = [4]float32{
+		float32(R) / 255.0,
+		float32(G) / 255.0,
+		float32(B) / 255.0,
+		float32(A) / 255.0,
+	}
+}
+
+// SetTextShadow draws the following text over a shadow moved by OffsetX,
+// OffsetY pixels whose edge fades over Softness pixels. Like outlines,
+// shadows need an SDF font, stay within its Spread and last across frames.
+// A fully transparent color turns the shadow off.
+func (app *App) SetTextShadow(OffsetX, OffsetY, Softness float32, R, G, B, A byte) {
+	app.state.textEffects.shadowOffsetX = OffsetX
+	app.state.textEffects.shadowOffsetY = OffsetY
+	app.state.textEffects.shadowSoftness = Softness
+	app.state.textEffects.shadowColor = [4]float32{
+		float32(R) / 255.0,
+		float32(G) / 255.0,
+		float32(B) / 255.0,
+		float32(A) / 255.0,
+	}
+}
+
+// ResetTextEffects removes the outline and the shadow.
+func (app *App) ResetTextEffects() {
+	app.state.textEffects = textEffects{}
+}
+
+// SetTextAlign sets where DrawTextBox puts the lines in its box. Justified
+// lines fill the width of the box except the last line of each paragraph,
+// which is left aligned. The text box settings last across frames.
+func (app *App) SetTextAlign(Horizontal TextAlign, Vertical TextVerticalAlign) {
+	app.state.textBox.align = Horizontal
+	app.state.textBox.verticalAlign = Vertical
+}
+func (app *App) GetTextAlign() (TextAlign, TextVerticalAlign) {
+	return app.state.textBox.align, app.state.textBox.verticalAlign
+}
+
+// SetLineSpacing multiplies the distance between the lines of a text box,
+// 1 by default.
+func (app *App) SetLineSpacing(Spacing float32) {
+	app.state.textBox.lineSpacing = Spacing
+}
+func (app *App) GetLineSpacing() float32 {
+	return app.state.textBox.lineSpacing
+}
+
+// SetEllipsis makes text boxes drop the lines that do not fit their height
+// and end the last one with an ellipsis.
+func (app *App) SetEllipsis(Enabled bool) {
+	app.state.textBox.ellipsis = Enabled
+}
+func (app *App) GetEllipsis() bool {
+	return app.state.textBox.ellipsis
+}
+
+// SetTabSize sets the distance between tab stops in spaces, 4 by default
+// and at least 1. It lasts across frames.
+func (app *App) SetTabSize(Spaces int) {
+	if Spaces < 1 {
+		Spaces = 1
+	}
+	app.state.tabSize = Spaces
+}
+func (app *App) GetTabSize() int {
+	return app.state.tabSize
 }
@@ -0,0 +1,38 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFromGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2},
+		Golden.Case{
+			Name: "fs_text", Width: 128, Height: 48,
+			Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+				// The same font read through an fs.FS must draw the same as the
+				// text case of Golden.Primitives.
+				font, err := app.LoadFontFS(os.DirFS(filepath.Dir(assets.FontPath)), filepath.Base(assets.FontPath), 16)
+				Golden.Must(t, err)
+				app.SetColor(255, 255, 255, 255)
+				app.DrawText(4, 4, 16, 0, 0, font, 1, "Overlay\nGo 123")
+			},
+		},
+		Golden.Case{
+			Name: "reader_image", Width: 64, Height: 64,
+			Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+				// The same image read from memory must draw the same as the
+				// image case of Golden.Primitives.
+				data, err := os.ReadFile(assets.ImagePath)
+				Golden.Must(t, err)
+				img, w, h, err := app.LoadImageFrom(bytes.NewReader(data))
+				Golden.Must(t, err)
+				app.DrawImage(8, 8, float32(w)*3, float32(h)*3, img)
+			},
+		},
+	)
+}
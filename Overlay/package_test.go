@@ -0,0 +1,137 @@
+package Overlay
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+var vcrPath = filepath.Join("..", "Fonts", "Vcr.ttf")
+
+func testFS(t *testing.T) fstest.MapFS {
+	t.Helper()
+	font, err := os.ReadFile(vcrPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.SetRGBA(1, 0, color.RGBA{R: 255, A: 255})
+	img.SetRGBA(2, 1, color.RGBA{G: 255, A: 255})
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatal(err)
+	}
+	return fstest.MapFS{
+		"fonts/vcr.ttf":  {Data: font},
+		"images/dot.png": {Data: encoded.Bytes()},
+	}
+}
+
+// render draws one frame of a 64x32 headless App.
+func render(t *testing.T, Draw func(app *App) error) []byte {
+	t.Helper()
+	app := NewHeadless(64, 32)
+	defer app.Dispose()
+	if err := Draw(&app); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Render(); err != nil {
+		t.Fatal(err)
+	}
+	return pixels(app.Frame())
+}
+
+func TestLoadFromFSAndBytes(t *testing.T) {
+	files := testFS(t)
+
+	drawImage := func(Load func(app *App) (ImageHandle, int, int, error)) func(app *App) error {
+		return func(app *App) error {
+			img, width, height, err := Load(app)
+			if err != nil {
+				return err
+			}
+			if width != 4 || height != 2 {
+				t.Errorf("image size %dx%d, want 4x2", width, height)
+			}
+			app.DrawImage(0, 0, 64, 32, img)
+			return nil
+		}
+	}
+	fromFS := render(t, drawImage(func(app *App) (ImageHandle, int, int, error) {
+		return app.LoadImageFS(files, "images/dot.png")
+	}))
+	fromReader := render(t, drawImage(func(app *App) (ImageHandle, int, int, error) {
+		return app.LoadImageFrom(bytes.NewReader(files["images/dot.png"].Data))
+	}))
+	if !bytes.Equal(fromFS, fromReader) || bytes.Equal(fromFS, make([]byte, len(fromFS))) {
+		t.Error("image from FS and from a reader differ or are empty")
+	}
+
+	drawText := func(Load func(app *App) (FontHandle, error)) func(app *App) error {
+		return func(app *App) error {
+			font, err := Load(app)
+			if err != nil {
+				return err
+			}
+			app.DrawText(2, 2, 16, 0, 0, font, 1, "Aby")
+			return nil
+		}
+	}
+	want := render(t, drawText(func(app *App) (FontHandle, error) {
+		return app.LoadFont(vcrPath, 16)
+	}))
+	if bytes.Equal(want, make([]byte, len(want))) {
+		t.Fatal("LoadFont draws no text")
+	}
+	loaders := map[string]func(app *App) (FontHandle, error){
+		"LoadFontFS": func(app *App) (FontHandle, error) {
+			return app.LoadFontFS(files, "fonts/vcr.ttf", 16)
+		},
+		"LoadFontFrom": func(app *App) (FontHandle, error) {
+			return app.LoadFontFrom(files["fonts/vcr.ttf"].Data, 16)
+		},
+		"LoadDynamicFontFS": func(app *App) (FontHandle, error) {
+			return app.LoadDynamicFontFS(files, "fonts/vcr.ttf", 16)
+		},
+		"LoadDynamicFontFrom": func(app *App) (FontHandle, error) {
+			return app.LoadDynamicFontFrom(files["fonts/vcr.ttf"].Data, 16)
+		},
+	}
+	for name, load := range loaders {
+		if got := render(t, drawText(load)); !bytes.Equal(got, want) {
+			t.Errorf("%s draws other text than LoadFont", name)
+		}
+	}
+}
+
+func TestLoadFromFSErrors(t *testing.T) {
+	files := testFS(t)
+	app := NewHeadless(8, 8)
+	defer app.Dispose()
+
+	var loadErr *LoadError
+	if _, _, _, err := app.LoadImageFS(files, "images/none.png"); !errors.Is(err, ErrNotFound) || !errors.As(err, &loadErr) {
+		t.Errorf("LoadImageFS of a missing file = %v", err)
+	}
+	if _, err := app.LoadFontFS(files, "fonts/none.ttf", 16); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadFontFS of a missing file = %v", err)
+	}
+	if _, err := app.LoadDynamicFontFS(files, "fonts/none.ttf", 16); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadDynamicFontFS of a missing file = %v", err)
+	}
+	if _, err := app.LoadFontFrom(files["images/dot.png"].Data, 16); !errors.As(err, &loadErr) || errors.Is(err, ErrNotFound) {
+		t.Errorf("LoadFontFrom of an image = %v", err)
+	}
+	if _, _, _, err := app.LoadImageFrom(bytes.NewReader(files["fonts/vcr.ttf"].Data)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("LoadImageFrom of a font = %v", err)
+	}
+	if assets := app.ListAssets(); len(assets) != 0 {
+		t.Errorf("failed loads left assets: %v", assets)
+	}
+}
@@ -0,0 +1,169 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Engine"
+	"DrawerGO/Overlay/Mesh"
+	"DrawerGO/Overlay/ttf2atlas"
+	"image"
+	"sort"
+)
+
+// Renderer draws the primitives recorded by App during a frame. The GL
+// renderer presents them in the overlay window, SoftwareRenderer rasterizes
+// them into an image.
+type Renderer interface {
+	// UploadTexture makes Img available for drawing and returns its id and size.
+	UploadTexture(Img image.Image) (uint32, int, int, error)
+	// UpdateTexture replaces the contents of a texture from UploadTexture.
+	UpdateTexture(TextureId uint32, Img image.Image) error
+	DeleteTexture(TextureId uint32)
+	Render(List *DrawList)
+	Dispose()
+}
+
+// Primitive is a recorded draw call: Line, Rect, OutlineRect, Image,
+// Polygon, Text or Circle.
+type Primitive interface {
+	// order is the key primitives are drawn by, lowest first.
+	order() uint32
+	appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont)
+}
+
+// DrawList holds everything recorded between two App.Render calls, grouped
+// into layers. Renderers draw the layers by their order and the commands of a
+// layer back to front by their ZIndex, which App assigns in submission order,
+// so translucent primitives blend over exactly what was drawn before them.
+type DrawList struct {
+	Layers []*Layer
+	Fonts  map[FontHandle]*loadedFont
+}
+
+func (list *DrawList) layer(Name string) *Layer {
+	for _, layer := range list.Layers {
+		if layer.name == Name {
+			return layer
+		}
+	}
+	layer := newLayer(Name)
+	list.Layers = append(list.Layers, layer)
+	return layer
+}
+
+// sortedLayers returns the layers by Order; the sort is stable so equal
+// orders keep their creation order.
+func (list *DrawList) sortedLayers() []*Layer {
+	layers := append([]*Layer{}, list.Layers...)
+	sort.SliceStable(layers, func(i, j int) bool {
+		return layers[i].order < layers[j].order
+	})
+	return layers
+}
+
+// Clear drops the commands of every layer but keeps the layers.
+func (list *DrawList) Clear() {
+	for _, layer := range list.Layers {
+		layer.commands = layer.commands[:0]
+	}
+}
+
+/*
+
+Models shared by every renderer. A model maps the mesh of a primitive to
+screen space: the transform of the App at draw time applied over the
+placement of the primitive itself.
+
+*/
+
+func boxModel(X, Y, Width, Height, AnchorPointX, AnchorPointY float32, Rotation Engine.Angle) Engine.Transform {
+	return Engine.Identity().Translate(X, Y).Rotate(Rotation).Translate(-AnchorPointX*Width, -AnchorPointY*Height).Scale(Width, Height)
+}
+
+// pivotModel rotates primitives given by absolute points, lines and
+// polygons, around the anchor point of their bounding box.
+func pivotModel(AnchorPointX, AnchorPointY float32, Rotation Engine.Angle, Points ...float32) Engine.Transform {
+	if Rotation == 0 {
+		return Engine.Identity()
+	}
+	minX, minY := Points[0], Points[1]
+	maxX, maxY := minX, minY
+	for i := 2; i+1 < len(Points); i += 2 {
+		if Points[i] < minX {
+			minX = Points[i]
+		}
+		if Points[i] > maxX {
+			maxX = Points[i]
+		}
+		if Points[i+1] < minY {
+			minY = Points[i+1]
+		}
+		if Points[i+1] > maxY {
+			maxY = Points[i+1]
+		}
+	}
+	pivotX := minX + AnchorPointX*(maxX-minX)
+	pivotY := minY + AnchorPointY*(maxY-minY)
+	return Engine.Identity().Translate(pivotX, pivotY).Rotate(Rotation).Translate(-pivotX, -pivotY)
+}
+
+func (v Line) model() Engine.Transform {
+	return v.Transform.Multiply(pivotModel(v.AnchorPointX, v.AnchorPointY, v.Rotation, v.X1, v.Y1, v.X2, v.Y2))
+}
+func (v Rect) model() Engine.Transform {
+	return v.Transform.Multiply(boxModel(v.X, v.Y, v.Width, v.Height, v.AnchorPointX, v.AnchorPointY, v.Rotation))
+}
+func (v OutlineRect) model() Engine.Transform {
+	return v.Transform.Multiply(boxModel(v.X, v.Y, v.Width, v.Height, v.AnchorPointX, v.AnchorPointY, v.Rotation))
+}
+func (v Image) model() Engine.Transform {
+	return v.Transform.Multiply(boxModel(v.X, v.Y, v.Width, v.Height, v.AnchorPointX, v.AnchorPointY, v.Rotation))
+}
+func (v Circle) model() Engine.Transform {
+	return v.Transform.Multiply(boxModel(v.X, v.Y, v.ScaleX, v.ScaleY, v.AnchorPointX, v.AnchorPointY, v.Rotation))
+}
+func (v Polygon) model() Engine.Transform {
+	return v.Transform.Multiply(pivotModel(v.AnchorPointX, v.AnchorPointY, v.Rotation, v.X1, v.Y1, v.X2, v.Y2, v.X3, v.Y3))
+}
+
+// model scales Layout, the pixel layout of the text, from the size Font was
+// rasterized at to Size, stretched by Width and Height, and anchors the
+// scaled text box.
+func (v Text) model(Layout Mesh.TextLayout, Font ttf2atlas.Font) Engine.Transform {
+	fontSize := Font.FontMetrics().FontSize
+	scaleX := (v.Size + v.Width) / fontSize
+	scaleY := (v.Size + v.Height) / fontSize
+	width := Layout.Width * scaleX
+	height := Layout.Height * scaleY
+	return v.Transform.Translate(v.X, v.Y).Rotate(v.Rotation).Translate(-v.AnchorPointX*width, -v.AnchorPointY*height).Scale(scaleX, scaleY)
+}
+
+// model of a TextBox scales the layout of Mesh.Box by Scale and anchors the
+// box rather than the text in it.
+func (v TextBox) model(Scale float32) Engine.Transform {
+	return v.Transform.Translate(v.X, v.Y).Rotate(v.Rotation).Translate(-v.AnchorPointX*v.BoxWidth, -v.AnchorPointY*v.BoxHeight).Scale(Scale, Scale)
+}
+
+// box converts the box to the pixels of the font, Scale times smaller.
+func (v TextBox) box(Scale float32) Mesh.Box {
+	box := Mesh.Box{
+		Width:       v.BoxWidth / Scale,
+		Height:      v.BoxHeight / Scale,
+		Justify:     v.Align == TEXT_ALIGN_JUSTIFY,
+		LineSpacing: v.LineSpacing,
+		Ellipsis:    v.Ellipsis,
+		TabSize:     v.TabSize,
+	}
+	switch v.Align {
+	case TEXT_ALIGN_CENTER:
+		box.AlignX = 0.5
+	case TEXT_ALIGN_RIGHT:
+		box.AlignX = 1
+	}
+	switch v.VerticalAlign {
+	case TEXT_ALIGN_MIDDLE:
+		box.AlignY = 0.5
+	case TEXT_ALIGN_BOTTOM:
+		box.AlignY = 1
+	}
+	return box
+}
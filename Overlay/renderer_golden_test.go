@@ -0,0 +1,23 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestRotationGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "rotation", Width: 64, Height: 64,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			app.RotateByDeg(30)
+			app.AnchorPoint(0.5, 0.5)
+			app.SetColor(255, 0, 0, 255)
+			app.DrawRect(20, 20, 24, 12)
+			app.SetColor(0, 255, 0, 255)
+			app.DrawLine(36, 44, 60, 44)
+			app.SetColor(0, 0, 255, 255)
+			app.DrawPolygon(8, 60, 20, 40, 32, 60)
+		},
+	})
+}
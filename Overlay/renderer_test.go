@@ -0,0 +1,89 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Engine"
+	"math"
+	"testing"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+// corners maps the corners of the unit square through Model.
+func corners(Model Engine.Transform) [4][2]float32 {
+	var out [4][2]float32
+	for i, corner := range [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+		out[i][0], out[i][1] = Model.Apply(corner[0], corner[1])
+	}
+	return out
+}
+
+func nearCorners(a, b [4][2]float32) bool {
+	for i := range a {
+		if !near(a[i][0], b[i][0]) || !near(a[i][1], b[i][1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBoxModelRotation(t *testing.T) {
+	// A 4x2 rect at 10,20 turned 90 degrees clockwise around its anchor.
+	tests := []struct {
+		anchorX, anchorY float32
+		want             [4][2]float32
+	}{
+		{0, 0, [4][2]float32{{10, 20}, {10, 24}, {8, 24}, {8, 20}}},
+		{0.5, 0.5, [4][2]float32{{11, 18}, {11, 22}, {9, 22}, {9, 18}}},
+		{1, 1, [4][2]float32{{12, 16}, {12, 20}, {10, 20}, {10, 16}}},
+	}
+	for _, test := range tests {
+		got := corners(boxModel(10, 20, 4, 2, test.anchorX, test.anchorY, Engine.Deg(90)))
+		if !nearCorners(got, test.want) {
+			t.Errorf("anchor %g,%g: corners %v, want %v", test.anchorX, test.anchorY, got, test.want)
+		}
+	}
+}
+
+func TestPivotModelRotation(t *testing.T) {
+	line := Line{X1: 0, Y1: 0, X2: 10, Y2: 0, AnchorPointX: 0.5, AnchorPointY: 0.5, Rotation: Engine.Deg(90), Transform: Engine.Identity()}
+	model := line.model()
+	x1, y1 := model.Apply(line.X1, line.Y1)
+	x2, y2 := model.Apply(line.X2, line.Y2)
+	if !near(x1, 5) || !near(y1, -5) || !near(x2, 5) || !near(y2, 5) {
+		t.Errorf("line turned around its midpoint = %g,%g %g,%g, want 5,-5 5,5", x1, y1, x2, y2)
+	}
+
+	if model := pivotModel(0.5, 0.5, 0, 0, 0, 10, 0); model != Engine.Identity() {
+		t.Errorf("model without rotation = %v", model)
+	}
+}
+
+// TestProgressBarRotation checks the background and the fill of a progress
+// bar turn together around the pivot of the background.
+func TestProgressBarRotation(t *testing.T) {
+	models := func(Rotation Engine.Angle) []Engine.Transform {
+		app := NewHeadless(64, 64)
+		defer app.Dispose()
+		app.AnchorPoint(0.5, 0.5)
+		app.SetRotation(Rotation)
+		app.DrawProgressBar(32, 32, 40, 8, 30, 100, PROGRESS_BAR_DIRECTION_RIGHT)
+		var out []Engine.Transform
+		for _, command := range app.GetLayer().commands {
+			out = append(out, command.(Rect).model())
+		}
+		return out
+	}
+	straight, turned := models(0), models(Engine.Deg(90))
+	if len(straight) != 2 || len(turned) != 2 {
+		t.Fatalf("progress bar drew %d rects", len(turned))
+	}
+	pivot := Engine.Identity().Translate(32, 32).Rotate(Engine.Deg(90)).Translate(-32, -32)
+	for i, name := range []string{"background", "fill"} {
+		want := corners(pivot.Multiply(straight[i]))
+		if got := corners(turned[i]); !nearCorners(got, want) {
+			t.Errorf("%s: corners %v, want %v", name, got, want)
+		}
+	}
+}
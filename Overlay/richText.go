@@ -0,0 +1,227 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Engine"
+	"DrawerGO/Overlay/Markup"
+	"DrawerGO/Overlay/Mesh"
+	"DrawerGO/Overlay/Type"
+	"strings"
+)
+
+// italicSkew slants italic runs, which fonts without an italic face do not
+// have.
+var italicSkew = Engine.Deg(-12)
+
+// richSegment is the part of a run on one line, laid out at the size of its
+// font and placed at X on the baseline of its line.
+type richSegment struct {
+	run         *richRun
+	loaded      *loadedFont
+	layout      Mesh.TextLayout
+	x, baseline float32
+	scale       float32
+	width       float32
+}
+
+// richLayout places the runs of a RichText, in pixels with the top left of
+// the block at 0, 0. Every line is as tall as its largest font needs and the
+// runs of a line share its baseline.
+type richLayout struct {
+	segments      []richSegment
+	width, height float32
+}
+
+func layoutRich(Runs []richRun, Fonts map[FontHandle]*loadedFont, Kerning bool, TabSize int) richLayout {
+	var layout richLayout
+	var line []richSegment
+	var pen, ascent, descent float32
+	// previous is the rune the next run is kerned against, when it is in
+	// the font of the run before it.
+	var previous rune = -1
+	var previousFont FontHandle
+	endLine := func() {
+		for i := range line {
+			line[i].baseline = layout.height + ascent
+		}
+		layout.segments = append(layout.segments, line...)
+		layout.height += ascent + descent
+		if pen > layout.width {
+			layout.width = pen
+		}
+		line, pen, ascent, descent = nil, 0, 0, 0
+		previous = -1
+	}
+	for i := range Runs {
+		run := &Runs[i]
+		loaded, ok := Fonts[run.font]
+		if !ok {
+			continue
+		}
+		metrics := loaded.font.FontMetrics()
+		if metrics.FontSize == 0 {
+			continue
+		}
+		scale := run.size / metrics.FontSize
+		for j, text := range strings.Split(run.text, "\n") {
+			if j > 0 {
+				endLine()
+			}
+			if metrics.Ascent*scale > ascent {
+				ascent = metrics.Ascent * scale
+			}
+			if (metrics.Descent+metrics.LineGap)*scale > descent {
+				descent = (metrics.Descent + metrics.LineGap) * scale
+			}
+			if text == "" {
+				continue
+			}
+			if run.font != previousFont {
+				previous = -1
+			}
+			// The run continues the line, so tabs reach the stops of the
+			// line and the first glyph kerns with the run before.
+			segment := richSegment{
+				run:    run,
+				loaded: loaded,
+				layout: Mesh.LayoutAfter(text, pen/scale, previous, loaded.font, 1, Kerning, TabSize),
+				x:      pen,
+				scale:  scale,
+			}
+			previous, previousFont = Mesh.LastRune(segment.layout.Lines[0]), run.font
+			segment.width = segment.layout.Width * scale
+			if run.bold {
+				segment.width += boldOffset(run.size)
+			}
+			pen += segment.width
+			line = append(line, segment)
+		}
+	}
+	endLine()
+	return layout
+}
+
+// boldOffset is how far the second copy of a bold glyph is moved right.
+func boldOffset(Size float32) float32 {
+	if Size < 20 {
+		return 1
+	}
+	return Size / 20
+}
+
+func (v RichText) appendTo(b *Batch.Batch, Fonts map[FontHandle]*loadedFont) {
+	layout := layoutRich(v.Runs, Fonts, v.Kerning, v.TabSize)
+	model := v.Transform.Translate(v.X, v.Y).Rotate(v.Rotation).Translate(-v.AnchorPointX*layout.width, -v.AnchorPointY*layout.height)
+	for _, segment := range layout.segments {
+		run := segment.run
+		text := v.Text
+		text.Color = run.color
+		pages := Mesh.Glyphs(segment.layout, 0)
+		glyphs := model.Translate(segment.x, segment.baseline)
+		if run.italic {
+			glyphs = glyphs.Skew(italicSkew, 0)
+		}
+		text.appendPages(b, segment.loaded, pages, glyphs.Scale(segment.scale, segment.scale))
+		if run.bold {
+			glyphs = glyphs.Translate(boldOffset(run.size), 0)
+			text.appendPages(b, segment.loaded, pages, glyphs.Scale(segment.scale, segment.scale))
+		}
+
+		thickness := run.size / 16
+		if thickness < 1 {
+			thickness = 1
+		}
+		if run.underline {
+			line := model.Translate(segment.x, segment.baseline+thickness).Scale(segment.width, thickness)
+			appendMesh(b, Mesh.Rect(), line, Type.Rectangle, true, run.color, 0, Batch.Effect{})
+		}
+		if run.strikethrough {
+			y := segment.baseline - segment.loaded.font.FontMetrics().Ascent*segment.scale*0.35
+			line := model.Translate(segment.x, y-thickness/2).Scale(segment.width, thickness)
+			appendMesh(b, Mesh.Rect(), line, Type.Rectangle, true, run.color, 0, Batch.Effect{})
+		}
+	}
+}
+
+// RegisterFont names a loaded font for the [font=Name] tag of DrawRichText.
+func (app *App) RegisterFont(Name string, Font FontHandle) {
+	app.context.fontNames[Name] = Font
+}
+
+// DrawRichText draws markup, text with tags like
+//
+//	app.DrawRichText(10, 10, 16, font, "[color=#ff0000]crit[/color] for [b]120[/b]")
+//
+// as one block; see package Markup for the tags. Text outside of tags uses
+// Font, Size and the current color; [font=Name] switches to a font given to
+// RegisterFont. Bold and italic are drawn by thickening and slanting the
+// glyphs of the font.
+func (app *App) DrawRichText(X, Y, Size float32, Font FontHandle, markup string) {
+	runs := app.richRuns(Size, Font, markup)
+	for _, run := range runs {
+		app.context.prepareText(run.font, run.text)
+	}
+	app.zIndex++
+	app.state.layer.add(RichText{
+		Text: Text{
+			X:            X,
+			Y:            Y,
+			Size:         Size,
+			Text:         markup,
+			Color:        app.state.color,
+			Rotation:     app.state.rotation,
+			Transform:    app.state.transform,
+			ZIndex:       app.zIndex,
+			AnchorPointX: app.state.anchorPointX,
+			AnchorPointY: app.state.anchorPointY,
+			Fill:         app.state.fill,
+			Font:         Font,
+			Interval:     1,
+			Kerning:      app.state.kerning,
+			TabSize:      app.state.tabSize,
+
+			OutlineWidth:   app.state.textEffects.outlineWidth,
+			OutlineColor:   app.state.textEffects.outlineColor,
+			ShadowOffsetX:  app.state.textEffects.shadowOffsetX,
+			ShadowOffsetY:  app.state.textEffects.shadowOffsetY,
+			ShadowSoftness: app.state.textEffects.shadowSoftness,
+			ShadowColor:    app.state.textEffects.shadowColor,
+		},
+		Runs: runs,
+	})
+}
+
+// MeasureRichText returns the width and height DrawRichText draws markup at.
+func (app *App) MeasureRichText(Size float32, Font FontHandle, markup string) (float32, float32) {
+	layout := layoutRich(app.richRuns(Size, Font, markup), app.context.drawList.Fonts, app.state.kerning, app.state.tabSize)
+	return layout.width, layout.height
+}
+
+// richRuns resolves the fonts, sizes and colors of the runs of markup.
+func (app *App) richRuns(Size float32, Font FontHandle, markup string) []richRun {
+	parsed := Markup.Parse(markup)
+	runs := make([]richRun, 0, len(parsed))
+	for _, run := range parsed {
+		out := richRun{
+			text:          run.Text,
+			font:          Font,
+			size:          Size,
+			color:         app.state.color,
+			bold:          run.Bold,
+			italic:        run.Italic,
+			underline:     run.Underline,
+			strikethrough: run.Strikethrough,
+		}
+		if font, ok := app.context.fontNames[run.Font]; ok {
+			out.font = font
+		}
+		if run.Size > 0 {
+			out.size = run.Size
+		}
+		if run.HasColor {
+			out.color = run.Color
+		}
+		runs = append(runs, out)
+	}
+	return runs
+}
@@ -0,0 +1,24 @@
+package Overlay_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestRichTextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "rich_text", Width: 192, Height: 80,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			font, err := app.LoadFont(assets.FontPath, 16)
+			Golden.Must(t, err)
+			big, err := app.LoadFont(assets.FontPath, 32)
+			Golden.Must(t, err)
+			app.RegisterFont("big", big)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawRichText(4, 4, 16, font, "[color=#ff4040]crit[/color] [b]120[/b] [i]fire[/i]\n"+
+				"[u]under[/u] [s]strike[/s] [color=#40ff40a0]half[/color]\n"+
+				"[font=big][size=24]Big[/size][/font] [[x] [size=10]small[/size]")
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Mesh"
+	"DrawerGO/Overlay/ttf2atlas"
+	"testing"
+)
+
+// richFonts has a font of size 10 and one of size 20 with a line gap, both
+// with a single glyph one and a half em wide.
+func richFonts() map[FontHandle]*loadedFont {
+	return map[FontHandle]*loadedFont{
+		1: newLoadedFont(&ttf2atlas.BitmapFont{
+			Metrics: ttf2atlas.Metrics{FontSize: 10, Ascent: 8, Descent: 2},
+			Glyphs:  map[rune]ttf2atlas.Glyph{'a': {Width: 5, Height: 6, Advance: 6}},
+		}),
+		2: newLoadedFont(&ttf2atlas.BitmapFont{
+			Metrics: ttf2atlas.Metrics{FontSize: 20, Ascent: 16, Descent: 4, LineGap: 2},
+			Glyphs:  map[rune]ttf2atlas.Glyph{'a': {Width: 10, Height: 12, Advance: 12}},
+		}),
+	}
+}
+
+func TestLayoutRich(t *testing.T) {
+	runs := []richRun{
+		{text: "aa", font: 1, size: 10},
+		// Font 2 at half its size, bold, across a line break.
+		{text: "a\na", font: 2, size: 10, bold: true},
+		{text: "aa", font: 1, size: 20},
+		// Runs in a font that is not loaded take no room.
+		{text: "aaaa", font: 9, size: 10},
+		// An empty last line is as tall as the font of its run.
+		{text: "\n", font: 1, size: 10},
+	}
+	layout := layoutRich(runs, richFonts(), false, Mesh.DefaultTabSize)
+
+	type segment struct {
+		glyphs                    int
+		x, baseline, scale, width float32
+	}
+	want := []segment{
+		{2, 0, 8, 1, 12},
+		{1, 12, 8, 0.5, 7},
+		{1, 0, 27, 0.5, 7},
+		{2, 7, 27, 2, 24},
+	}
+	if len(layout.segments) != len(want) {
+		t.Fatalf("%d segments, want %d", len(layout.segments), len(want))
+	}
+	for i, s := range layout.segments {
+		got := segment{len(s.layout.Lines[0].Glyphs), s.x, s.baseline, s.scale, s.width}
+		if got != want[i] {
+			t.Errorf("segment %d of %q = %+v, want %+v", i, s.run.text, got, want[i])
+		}
+	}
+	// Line 1 is 8 above and 3 below the baseline, line 2 16 and 4, line 3 8
+	// and 2.
+	if layout.width != 31 || layout.height != 41 {
+		t.Errorf("size %gx%g, want 31x41", layout.width, layout.height)
+	}
+}
+
+func TestLayoutRichWithoutFonts(t *testing.T) {
+	layout := layoutRich([]richRun{{text: "aa", font: 9, size: 10}}, richFonts(), false, Mesh.DefaultTabSize)
+	if len(layout.segments) != 0 || layout.width != 0 || layout.height != 0 {
+		t.Errorf("layout = %+v, want nothing", layout)
+	}
+}
+
+func TestLayoutRichContinuesLines(t *testing.T) {
+	fonts := richFonts()
+	fonts[1].font.(*ttf2atlas.BitmapFont).Kerning = map[ttf2atlas.KerningPair]float32{{Left: 'a', Right: 'a'}: -2}
+	// Without a space glyph tab stops are 4 half ems, 20 pixels, apart.
+	runs := []richRun{
+		{text: "a", font: 1, size: 10},
+		{text: "\ta", font: 1, size: 10, color: [4]float32{1, 0, 0, 1}},
+		{text: "a", font: 1, size: 10, bold: true},
+		{text: "a", font: 2, size: 20},
+	}
+	layout := layoutRich(runs, fonts, true, Mesh.DefaultTabSize)
+	if len(layout.segments) != 4 {
+		t.Fatalf("%d segments, want 4", len(layout.segments))
+	}
+	// The tab reaches the stop of the line at 20, not 20 past the run.
+	colored := layout.segments[1]
+	if a := colored.layout.Lines[0].Glyphs[1]; colored.x != 6 || a.X != 14 || colored.width != 20 {
+		t.Errorf("colored run at %g with the a at %g and width %g, want 6, 14 and 20", colored.x, a.X, colored.width)
+	}
+	// The bold run is kerned against the a before it, the run in another
+	// font is not.
+	if x := layout.segments[2].layout.Lines[0].Glyphs[0].X; x != -2 {
+		t.Errorf("bold a at %g, want -2", x)
+	}
+	if x := layout.segments[3].layout.Lines[0].Glyphs[0].X; x != 0 {
+		t.Errorf("a in font 2 at %g, want 0", x)
+	}
+}
@@ -0,0 +1,151 @@
+package Overlay
+
+import (
+	"DrawerGO/Overlay/Batch"
+	"DrawerGO/Overlay/Raster"
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// SoftwareRenderer rasterizes the frame on the CPU into an RGBA image. It
+// needs neither a GL context nor a window, so it works on machines without a
+// GPU and lets tests inspect what the draw calls produce.
+type SoftwareRenderer struct {
+	canvas        *Raster.Canvas
+	textures      map[uint32]*image.RGBA
+	nextTextureId uint32
+	batch         Batch.Batch
+}
+
+func NewSoftwareRenderer(Width, Height int) *SoftwareRenderer {
+	return &SoftwareRenderer{
+		canvas:        Raster.NewCanvas(Width, Height),
+		textures:      map[uint32]*image.RGBA{},
+		nextTextureId: 1,
+	}
+}
+
+// Image returns the target of the last Render call.
+func (r *SoftwareRenderer) Image() *image.RGBA {
+	return r.canvas.Target
+}
+
+func (r *SoftwareRenderer) UploadTexture(Img image.Image) (uint32, int, int, error) {
+	if Img == nil {
+		return 0, 0, 0, fmt.Errorf("upload texture: nil image")
+	}
+	rgba := copyRGBA(Img)
+
+	id := r.nextTextureId
+	r.nextTextureId++
+	r.textures[id] = rgba
+	size := rgba.Rect.Size()
+	return id, size.X, size.Y, nil
+}
+
+func (r *SoftwareRenderer) UpdateTexture(TextureId uint32, Img image.Image) error {
+	if _, ok := r.textures[TextureId]; !ok {
+		return fmt.Errorf("update texture: unknown texture %d", TextureId)
+	}
+	if Img == nil {
+		return fmt.Errorf("update texture: nil image")
+	}
+	r.textures[TextureId] = copyRGBA(Img)
+	return nil
+}
+
+func (r *SoftwareRenderer) DeleteTexture(TextureId uint32) {
+	delete(r.textures, TextureId)
+}
+
+func (r *SoftwareRenderer) Dispose() {
+	r.textures = map[uint32]*image.RGBA{}
+}
+
+func (r *SoftwareRenderer) Render(List *DrawList) {
+	r.canvas.Clear()
+	r.batch.Reset()
+	List.build(&r.batch)
+
+	for _, call := range r.batch.Calls {
+		texture := r.textures[call.Texture]
+		vertices := r.batch.Vertices[call.First : call.First+call.Count]
+		switch call.Mode {
+		case Batch.Lines:
+			for i := 0; i+1 < call.Count; i += 2 {
+				a, b := vertices[i], vertices[i+1]
+				r.canvas.DrawLine(rasterVertex(a), rasterVertex(b), a.Color, texture)
+			}
+		case Batch.Triangles:
+			for i := 0; i+2 < call.Count; i += 3 {
+				a, b, c := vertices[i], vertices[i+1], vertices[i+2]
+				if call.Effect.SDF && texture != nil {
+					r.canvas.FillTriangleShaded(rasterVertex(a), rasterVertex(b), rasterVertex(c), shadeSDF(texture, call.Effect, a.Color))
+					continue
+				}
+				r.canvas.FillTriangle(rasterVertex(a), rasterVertex(b), rasterVertex(c), a.Color, texture)
+			}
+		}
+	}
+}
+
+// shadeSDF does what the SDF branch of Shader.RendererFragmentShader does.
+func shadeSDF(Texture *image.RGBA, Effect Batch.Effect, Color [4]float32) func(U, V float32) [4]float32 {
+	return func(U, V float32) [4]float32 {
+		d := Raster.Sample(Texture, U, V)[3]
+		color := Color
+		color[3] *= smoothstep(0.5-Effect.Smoothing, 0.5+Effect.Smoothing, d)
+		if Effect.OutlineWidth > 0 {
+			edge := 0.5 - Effect.OutlineWidth
+			outline := Effect.OutlineColor
+			outline[3] *= Color[3] * smoothstep(edge-Effect.Smoothing, edge+Effect.Smoothing, d)
+			color = over(color, outline)
+		}
+		if Effect.ShadowColor[3] > 0 {
+			s := Raster.Sample(Texture, U-Effect.ShadowOffset[0], V-Effect.ShadowOffset[1])[3]
+			shadow := Effect.ShadowColor
+			shadow[3] *= Color[3] * smoothstep(0.5-Effect.ShadowSoftness-Effect.Smoothing, 0.5+Effect.Smoothing, s)
+			color = over(color, shadow)
+		}
+		return color
+	}
+}
+
+// over composites two colors with straight alpha.
+func over(Top, Bottom [4]float32) [4]float32 {
+	a := Top[3] + Bottom[3]*(1-Top[3])
+	if a == 0 {
+		return [4]float32{}
+	}
+	var result [4]float32
+	for i := 0; i < 3; i++ {
+		result[i] = (Top[i]*Top[3] + Bottom[i]*Bottom[3]*(1-Top[3])) / a
+	}
+	result[3] = a
+	return result
+}
+
+// smoothstep matches the GLSL function of the same name.
+func smoothstep(Edge0, Edge1, X float32) float32 {
+	t := (X - Edge0) / (Edge1 - Edge0)
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	return t * t * (3 - 2*t)
+}
+
+// copyRGBA copies Img into a new RGBA image with its origin at 0, 0.
+func copyRGBA(Img image.Image) *image.RGBA {
+	rgba := image.NewRGBA(image.Rect(0, 0, Img.Bounds().Dx(), Img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), Img, Img.Bounds().Min, draw.Src)
+	return rgba
+}
+
+// rasterVertex drops the color of v, the canvas takes it once per primitive.
+func rasterVertex(v Batch.Vertex) Raster.Vertex {
+	return Raster.Vertex{X: v.X, Y: v.Y, U: v.U, V: v.V}
+}
@@ -0,0 +1,86 @@
+package ttf2atlas
+
+// Font is what text layout needs from a loaded font. FontAtlas is a font
+// whose glyphs were all rasterized up front, GlyphCache one that rasterizes
+// them on first use.
+type Font interface {
+	FontMetrics() Metrics
+	// Glyph returns the glyph of Rune, or false when it cannot be drawn.
+	Glyph(Rune rune) (Glyph, bool)
+	// Kern returns the pixels to add to the advance of Left when Right
+	// follows it.
+	Kern(Left, Right rune) float32
+	// PageCount is the most atlas images the glyphs of the font are ever
+	// spread over.
+	PageCount() int
+}
+
+// Metrics are the vertical metrics of a font in pixels. Ascent is the height
+// above the baseline and Descent the depth below it, both positive, and
+// LineGap the extra space the font asks for between two lines.
+type Metrics struct {
+	FontSize                 float32
+	Ascent, Descent, LineGap float32
+}
+
+// LineHeight is the distance between the baselines of two lines.
+func (metrics Metrics) LineHeight() float32 {
+	return metrics.Ascent + metrics.Descent + metrics.LineGap
+}
+
+// Glyph is where a rune lives in the atlas and how it sits on the baseline.
+// Page is the atlas image the glyph is on, always 0 for a FontAtlas. X, Y,
+// Width and Height are the pixel rectangle of the glyph in that image and
+// U0, V0, U1, V1 the same rectangle in texture coordinates.
+// BearingX is the distance from the pen position to the left edge of that
+// rectangle, BearingY from the baseline up to its top edge, and Advance how
+// far the pen moves after the glyph, all in pixels. The glyph is drawn
+// Scale times the size of its rectangle, which a Family sets for fonts of
+// another FontSize; 0 means 1.
+type Glyph struct {
+	Page                int
+	X, Y, Width, Height int
+	U0, V0, U1, V1      float32
+	BearingX, BearingY  float32
+	Advance             float32
+	Scale               float32
+}
+
+func (glyph *Glyph) setUV(AtlasWidth, AtlasHeight int) {
+	glyph.U0 = float32(glyph.X) / float32(AtlasWidth)
+	glyph.V0 = float32(glyph.Y) / float32(AtlasHeight)
+	glyph.U1 = float32(glyph.X+glyph.Width) / float32(AtlasWidth)
+	glyph.V1 = float32(glyph.Y+glyph.Height) / float32(AtlasHeight)
+}
+
+// FontAtlas describes the image built by FontToAtlas. Kerning holds the
+// pixels to add to the advance of Left when Right follows it. When SDF is
+// set the alpha of the image is a signed distance field reaching Spread
+// pixels past the outline, see Options.
+type FontAtlas struct {
+	Metrics
+	Width, Height int
+	Glyphs        map[rune]Glyph
+	Kerning       map[KerningPair]float32
+	SDF           bool
+	Spread        float32
+}
+
+func (atlas *FontAtlas) FontMetrics() Metrics {
+	return atlas.Metrics
+}
+
+// Glyph returns the glyph of Rune, or false when the font has none.
+func (atlas *FontAtlas) Glyph(Rune rune) (Glyph, bool) {
+	glyph, ok := atlas.Glyphs[Rune]
+	return glyph, ok
+}
+
+// Kern returns the kerning between Left and Right, 0 when there is none.
+func (atlas *FontAtlas) Kern(Left, Right rune) float32 {
+	return atlas.Kerning[KerningPair{Left: Left, Right: Right}]
+}
+
+func (atlas *FontAtlas) PageCount() int {
+	return 1
+}
@@ -0,0 +1,203 @@
+package ttf2atlas
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// atlasFileVersion changes whenever atlases are built differently, so
+// files written by older builds stop matching.
+const atlasFileVersion = 1
+
+// atlasFile is the JSON written next to the PNG of a saved atlas.
+type atlasFile struct {
+	Version       int
+	Key           string
+	Metrics       Metrics
+	Width, Height int
+	SDF           bool
+	Spread        float32
+	Glyphs        []glyphEntry
+	Kerning       []kerningEntry
+}
+
+type glyphEntry struct {
+	Rune rune
+	Glyph
+}
+
+type kerningEntry struct {
+	Left, Right rune
+	Value       float32
+}
+
+// CacheKey identifies the atlas FontToAtlasWithOptions builds from a font
+// file with the contents FontData: it changes with the font, the size, the
+// runes and every other option.
+func CacheKey(FontData []byte, FontSize float32, Opts Options) string {
+	hash := sha256.New()
+	fontHash := sha256.Sum256(FontData)
+	hash.Write(fontHash[:])
+	binary.Write(hash, binary.LittleEndian, []int32{
+		atlasFileVersion,
+		int32(math.Float32bits(FontSize)),
+		int32(Opts.padding()),
+		int32(Opts.Spread),
+	})
+	binary.Write(hash, binary.LittleEndian, Opts.SDF)
+	binary.Write(hash, binary.LittleEndian, Opts.runes())
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// FontToAtlasCached is FontToAtlasWithOptions with the result kept in
+// CacheDir. The atlas is loaded from there when a matching one was saved
+// before, otherwise it is built and saved for the next time. Failing to save
+// does not fail the call, the atlas is just built again next time.
+func FontToAtlasCached(FontPath string, FontSize float32, Opts Options, CacheDir string) (*image.RGBA, *FontAtlas, error) {
+	data, err := os.ReadFile(FontPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(FontPath), filepath.Ext(FontPath))
+	return FontDataToAtlasCached(name, data, FontSize, Opts, CacheDir)
+}
+
+// FontDataToAtlasCached is FontToAtlasCached for a font file already in
+// memory. Name only labels the files in CacheDir; the key tells fonts
+// apart.
+func FontDataToAtlasCached(Name string, Data []byte, FontSize float32, Opts Options, CacheDir string) (*image.RGBA, *FontAtlas, error) {
+	if err := Opts.check(); err != nil {
+		return nil, nil, err
+	}
+	key := CacheKey(Data, FontSize, Opts)
+	path := filepath.Join(CacheDir, fmt.Sprintf("%s-%g-%s", Name, FontSize, key))
+	// The key hashes Data, and atlases are only saved for fonts that parsed,
+	// so a hit needs no parsing.
+	if img, atlas, err := loadAtlas(path, key); err == nil {
+		return img, atlas, nil
+	}
+	ttf, err := parseFont(Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, atlas := buildAtlas(Data, ttf, FontSize, Opts)
+	if err := os.MkdirAll(CacheDir, 0o755); err == nil {
+		saveAtlas(path, key, img, atlas)
+	}
+	return img, atlas, nil
+}
+
+// SaveAtlas writes Img to Path.png and Atlas to Path.json.
+func SaveAtlas(Path string, Img *image.RGBA, Atlas *FontAtlas) error {
+	return saveAtlas(Path, "", Img, Atlas)
+}
+
+// LoadAtlas reads an atlas written by SaveAtlas.
+func LoadAtlas(Path string) (*image.RGBA, *FontAtlas, error) {
+	return loadAtlas(Path, "")
+}
+
+func saveAtlas(Path, Key string, Img *image.RGBA, Atlas *FontAtlas) error {
+	file := atlasFile{
+		Version: atlasFileVersion,
+		Key:     Key,
+		Metrics: Atlas.Metrics,
+		Width:   Atlas.Width,
+		Height:  Atlas.Height,
+		SDF:     Atlas.SDF,
+		Spread:  Atlas.Spread,
+	}
+	for r, glyph := range Atlas.Glyphs {
+		file.Glyphs = append(file.Glyphs, glyphEntry{Rune: r, Glyph: glyph})
+	}
+	sort.Slice(file.Glyphs, func(i, j int) bool {
+		return file.Glyphs[i].Rune < file.Glyphs[j].Rune
+	})
+	for pair, value := range Atlas.Kerning {
+		file.Kerning = append(file.Kerning, kerningEntry{Left: pair.Left, Right: pair.Right, Value: value})
+	}
+	sort.Slice(file.Kerning, func(i, j int) bool {
+		a, b := file.Kerning[i], file.Kerning[j]
+		return a.Left < b.Left || (a.Left == b.Left && a.Right < b.Right)
+	})
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(Path + ".png")
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, Img); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// The JSON goes last: an atlas is only found once both files are whole.
+	return os.WriteFile(Path+".json", data, 0o644)
+}
+
+// loadAtlas reads Path.json and Path.png. A Key other than "" must match
+// the key the atlas was saved with.
+func loadAtlas(Path, Key string) (*image.RGBA, *FontAtlas, error) {
+	data, err := os.ReadFile(Path + ".json")
+	if err != nil {
+		return nil, nil, err
+	}
+	var file atlasFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("load atlas %s: %w: %v", Path, ErrDecode, err)
+	}
+	if file.Version != atlasFileVersion {
+		return nil, nil, fmt.Errorf("load atlas %s: %w: version %d, want %d", Path, ErrUnsupportedFormat, file.Version, atlasFileVersion)
+	}
+	if Key != "" && file.Key != Key {
+		return nil, nil, fmt.Errorf("load atlas %s: key does not match", Path)
+	}
+
+	in, err := os.Open(Path + ".png")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer in.Close()
+	decoded, err := png.Decode(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load atlas %s: %w: %v", Path, ErrDecode, err)
+	}
+	if decoded.Bounds().Dx() != file.Width || decoded.Bounds().Dy() != file.Height {
+		return nil, nil, fmt.Errorf("load atlas %s: %w: image is %v, want %dx%d", Path, ErrDecode, decoded.Bounds().Size(), file.Width, file.Height)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, file.Width, file.Height))
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+
+	atlas := &FontAtlas{
+		Metrics: file.Metrics,
+		Width:   file.Width,
+		Height:  file.Height,
+		Glyphs:  make(map[rune]Glyph, len(file.Glyphs)),
+		Kerning: make(map[KerningPair]float32, len(file.Kerning)),
+		SDF:     file.SDF,
+		Spread:  file.Spread,
+	}
+	for _, entry := range file.Glyphs {
+		atlas.Glyphs[entry.Rune] = entry.Glyph
+	}
+	for _, entry := range file.Kerning {
+		atlas.Kerning[KerningPair{Left: entry.Left, Right: entry.Right}] = entry.Value
+	}
+	return img, atlas, nil
+}
@@ -0,0 +1,25 @@
+package ttf2atlas_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"testing"
+)
+
+func TestCachedTextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "cached_text", Width: 128, Height: 48,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			// The second load reads the atlas the first one saved and must draw
+			// the same as the text case of Golden.Primitives.
+			app.SetFontCacheDir(t.TempDir())
+			first, err := app.LoadFont(assets.FontPath, 16)
+			Golden.Must(t, err)
+			app.ReleaseFont(first)
+			font, err := app.LoadFont(assets.FontPath, 16)
+			Golden.Must(t, err)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(4, 4, 16, 0, 0, font, 1, "Overlay\nGo 123")
+		},
+	})
+}
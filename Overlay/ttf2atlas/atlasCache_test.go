@@ -0,0 +1,157 @@
+package ttf2atlas
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readVcr(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCacheKey(t *testing.T) {
+	data := readVcr(t)
+	opts := Options{Ranges: []Range{BasicLatin}, Padding: 1}
+	key := CacheKey(data, 16, opts)
+
+	same := map[string]string{
+		"same options": CacheKey(data, 16, Options{Ranges: []Range{BasicLatin}, Padding: 1}),
+		"same runes":   CacheKey(data, 16, Options{Ranges: []Range{{First: 0x50, Last: 0x7e}, {First: 0x20, Last: 0x60}}, Padding: 1}),
+		"no padding":   CacheKey(data, 16, Options{Ranges: []Range{BasicLatin}}),
+	}
+	for name, other := range same {
+		if other != key {
+			t.Errorf("%s: key %s, want %s", name, other, key)
+		}
+	}
+	changed := map[string]string{
+		"size":    CacheKey(data, 17, opts),
+		"padding": CacheKey(data, 16, Options{Ranges: []Range{BasicLatin}, Padding: 2}),
+		"sdf":     CacheKey(data, 16, Options{Ranges: []Range{BasicLatin}, Padding: 1, SDF: true}),
+		"spread":  CacheKey(data, 16, Options{Ranges: []Range{BasicLatin}, Padding: 1, Spread: 6}),
+		"ranges":  CacheKey(data, 16, Options{Ranges: []Range{Latin1Supplement}, Padding: 1}),
+		"charset": CacheKey(data, 16, Options{Ranges: []Range{BasicLatin}, Charset: "é", Padding: 1}),
+		"font":    CacheKey(append(append([]byte{}, data...), 0), 16, opts),
+	}
+	for name, other := range changed {
+		if other == key {
+			t.Errorf("key does not change with the %s", name)
+		}
+	}
+}
+
+func TestAtlasCacheRoundTrip(t *testing.T) {
+	data := readVcr(t)
+	dir := filepath.Join(t.TempDir(), "cache")
+	opts := Options{Ranges: []Range{BasicLatin}, Padding: 1}
+
+	img, atlas, err := FontDataToAtlasCached("vcr", data, 16, opts, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "vcr-16-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("cache holds %v, want one atlas", files)
+	}
+	cached, cachedAtlas, err := FontDataToAtlasCached("vcr", data, 16, opts, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cached, img) || !reflect.DeepEqual(cachedAtlas, atlas) {
+		t.Error("the cached atlas differs from the built one")
+	}
+
+	// Change the saved file to tell a load from a rebuild.
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file atlasFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		t.Fatal(err)
+	}
+	file.Metrics.LineGap = 7
+	raw, _ = json.Marshal(file)
+	if err := os.WriteFile(files[0], raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, loaded, err := FontDataToAtlasCached("vcr", data, 16, opts, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.LineGap != 7 {
+		t.Errorf("second load has line gap %g, want the 7 saved", loaded.LineGap)
+	}
+	// Other options miss the cache and add an atlas of their own.
+	_, built, err := FontDataToAtlasCached("vcr", data, 16, Options{Ranges: []Range{BasicLatin}, Padding: 2}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.LineGap == 7 {
+		t.Error("load with other options read the saved atlas")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "vcr-16-*.json")); len(files) != 2 {
+		t.Errorf("cache holds %v, want two atlases", files)
+	}
+}
+
+func TestSaveAndLoadAtlas(t *testing.T) {
+	img, atlas, err := FontDataToAtlas(readVcr(t), 16, Options{Ranges: []Range{BasicLatin}, Padding: 1, SDF: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	atlas.Kerning = map[KerningPair]float32{{Left: 'A', Right: 'V'}: -2, {Left: 'T', Right: 'o'}: -1.5}
+	path := filepath.Join(t.TempDir(), "vcr")
+	if err := SaveAtlas(path, img, atlas); err != nil {
+		t.Fatal(err)
+	}
+	loadedImg, loaded, err := LoadAtlas(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loadedImg, img) || !reflect.DeepEqual(loaded, atlas) {
+		t.Error("the loaded atlas differs from the saved one")
+	}
+
+	if _, _, err := loadAtlas(path, "other"); err == nil {
+		t.Error("an atlas saved without a key matches a key")
+	}
+	raw, _ := json.Marshal(atlasFile{Version: atlasFileVersion + 1})
+	if err := os.WriteFile(path+".json", raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadAtlas(path); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("LoadAtlas of a newer file = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+// TestCacheHitSkipsParsing stores an atlas under the key of bytes that are
+// no font: only a load that does not parse them can return it.
+func TestCacheHitSkipsParsing(t *testing.T) {
+	img, atlas, err := FontDataToAtlas(readVcr(t), 16, Options{Ranges: []Range{BasicLatin}, Padding: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	data := []byte("not a font")
+	opts := Options{Ranges: []Range{BasicLatin}, Padding: 1}
+	key := CacheKey(data, 16, opts)
+	if err := saveAtlas(filepath.Join(dir, "stub-16-"+key), key, img, atlas); err != nil {
+		t.Fatal(err)
+	}
+	if _, loaded, err := FontDataToAtlasCached("stub", data, 16, opts, dir); err != nil || !reflect.DeepEqual(loaded, atlas) {
+		t.Errorf("cache hit = %v, want the saved atlas", err)
+	}
+	if _, _, err := FontDataToAtlasCached("stub", data, 17, opts, dir); !errors.Is(err, ErrDecode) && !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("cache miss = %v, want the parse error", err)
+	}
+}
@@ -0,0 +1,352 @@
+package ttf2atlas
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BitmapFont is a font drawn by an artist into page images, described by an
+// AngelCode BMFont .fnt file. The glyphs of a page have Page set to its
+// index in Pages.
+type BitmapFont struct {
+	Metrics
+	Pages   []string
+	Glyphs  map[rune]Glyph
+	Kerning map[KerningPair]float32
+}
+
+func (bitmap *BitmapFont) FontMetrics() Metrics {
+	return bitmap.Metrics
+}
+
+func (bitmap *BitmapFont) Glyph(Rune rune) (Glyph, bool) {
+	glyph, ok := bitmap.Glyphs[Rune]
+	return glyph, ok
+}
+
+func (bitmap *BitmapFont) Kern(Left, Right rune) float32 {
+	return bitmap.Kerning[KerningPair{Left: Left, Right: Right}]
+}
+
+func (bitmap *BitmapFont) PageCount() int {
+	return len(bitmap.Pages)
+}
+
+// bmFont is what a .fnt file says, in either of its text forms.
+type bmFont struct {
+	Info struct {
+		Size int `xml:"size,attr"`
+	} `xml:"info"`
+	Common struct {
+		LineHeight int `xml:"lineHeight,attr"`
+		Base       int `xml:"base,attr"`
+		ScaleW     int `xml:"scaleW,attr"`
+		ScaleH     int `xml:"scaleH,attr"`
+		Packed     int `xml:"packed,attr"`
+		AlphaChnl  int `xml:"alphaChnl,attr"`
+		RedChnl    int `xml:"redChnl,attr"`
+		GreenChnl  int `xml:"greenChnl,attr"`
+		BlueChnl   int `xml:"blueChnl,attr"`
+	} `xml:"common"`
+	Pages []struct {
+		Id   int    `xml:"id,attr"`
+		File string `xml:"file,attr"`
+	} `xml:"pages>page"`
+	Chars []struct {
+		Id       rune `xml:"id,attr"`
+		X        int  `xml:"x,attr"`
+		Y        int  `xml:"y,attr"`
+		Width    int  `xml:"width,attr"`
+		Height   int  `xml:"height,attr"`
+		XOffset  int  `xml:"xoffset,attr"`
+		YOffset  int  `xml:"yoffset,attr"`
+		XAdvance int  `xml:"xadvance,attr"`
+		Page     int  `xml:"page,attr"`
+	} `xml:"chars>char"`
+	Kernings []struct {
+		First  rune `xml:"first,attr"`
+		Second rune `xml:"second,attr"`
+		Amount int  `xml:"amount,attr"`
+	} `xml:"kernings>kerning"`
+}
+
+// LoadBMFont reads a BMFont descriptor in the text or the XML format and
+// the page images it names, which are looked up next to it. The pages are
+// returned in order, ready to upload. Pages that keep the glyphs in their
+// color channels and leave alpha unused are turned into white glyphs on
+// transparent, taken from the one color channel that holds glyphs or from
+// the luminance when several do. Pages with alpha are kept as they are, so
+// colored pixel fonts keep their colors when drawn white. Packed pages, with
+// different glyphs in each channel, are not supported.
+func LoadBMFont(Path string) (*BitmapFont, []*image.RGBA, error) {
+	data, err := os.ReadFile(Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loadBMFont(data, func(Page string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(filepath.Dir(Path), Page))
+	})
+}
+
+// LoadBMFontFS is LoadBMFont reading the descriptor and its pages from
+// FS, for example an embed.FS.
+func LoadBMFontFS(FS fs.FS, Path string) (*BitmapFont, []*image.RGBA, error) {
+	data, err := fs.ReadFile(FS, Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loadBMFont(data, func(Page string) (io.ReadCloser, error) {
+		return FS.Open(path.Join(path.Dir(Path), Page))
+	})
+}
+
+// loadBMFont parses the descriptor Data and reads every page it names with
+// OpenPage.
+func loadBMFont(Data []byte, OpenPage func(Page string) (io.ReadCloser, error)) (*BitmapFont, []*image.RGBA, error) {
+	var desc bmFont
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(Data, []byte("\xef\xbb\xbf")))
+	var err error
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("BMF")):
+		return nil, nil, fmt.Errorf("bmfont: %w: binary descriptor", ErrUnsupportedFormat)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		err = xml.Unmarshal(trimmed, &desc)
+	default:
+		err = parseBMFontText(trimmed, &desc)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("bmfont: %w: %v", ErrDecode, err)
+	}
+	if desc.Common.Packed != 0 {
+		return nil, nil, fmt.Errorf("bmfont: %w: packed pages", ErrUnsupportedFormat)
+	}
+	if desc.Common.ScaleW <= 0 || desc.Common.ScaleH <= 0 || len(desc.Pages) == 0 {
+		return nil, nil, fmt.Errorf("bmfont: %w: no pages", ErrDecode)
+	}
+
+	size := desc.Info.Size
+	if size < 0 {
+		size = -size
+	}
+	if size == 0 {
+		size = desc.Common.LineHeight
+	}
+	font := &BitmapFont{
+		Metrics: Metrics{
+			FontSize: float32(size),
+			Ascent:   float32(desc.Common.Base),
+			Descent:  float32(desc.Common.LineHeight - desc.Common.Base),
+		},
+		Pages:   make([]string, len(desc.Pages)),
+		Glyphs:  make(map[rune]Glyph, len(desc.Chars)),
+		Kerning: make(map[KerningPair]float32, len(desc.Kernings)),
+	}
+	for _, page := range desc.Pages {
+		if page.Id < 0 || page.Id >= len(font.Pages) {
+			return nil, nil, fmt.Errorf("bmfont: %w: page id %d out of range", ErrDecode, page.Id)
+		}
+		font.Pages[page.Id] = page.File
+	}
+	for _, char := range desc.Chars {
+		if char.Page < 0 || char.Page >= len(font.Pages) {
+			return nil, nil, fmt.Errorf("bmfont: %w: char %d is on missing page %d", ErrDecode, char.Id, char.Page)
+		}
+		glyph := Glyph{
+			Page:     char.Page,
+			X:        char.X,
+			Y:        char.Y,
+			Width:    char.Width,
+			Height:   char.Height,
+			BearingX: float32(char.XOffset),
+			BearingY: float32(desc.Common.Base - char.YOffset),
+			Advance:  float32(char.XAdvance),
+		}
+		glyph.setUV(desc.Common.ScaleW, desc.Common.ScaleH)
+		font.Glyphs[char.Id] = glyph
+	}
+	for _, kerning := range desc.Kernings {
+		font.Kerning[KerningPair{Left: kerning.First, Right: kerning.Second}] += float32(kerning.Amount)
+	}
+
+	coverage := desc.coverage()
+	pages := make([]*image.RGBA, len(font.Pages))
+	for i, file := range font.Pages {
+		if file == "" {
+			return nil, nil, fmt.Errorf("bmfont: %w: page %d has no file", ErrDecode, i)
+		}
+		page, err := loadPage(file, OpenPage, coverage)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bmfont: %w", err)
+		}
+		pages[i] = page
+	}
+	return font, pages, nil
+}
+
+// coverage returns how to read the glyphs of a page whose alpha channel is
+// unused, or nil when the alpha holds them. A channel value of 0 or 2 means
+// glyphs, with or without their outline; 3 and 4 a channel of all zero or
+// all one.
+func (desc *bmFont) coverage() func(R, G, B uint8) uint8 {
+	if desc.Common.AlphaChnl != 3 && desc.Common.AlphaChnl != 4 {
+		return nil
+	}
+	glyphs := func(Chnl int) bool {
+		return Chnl == 0 || Chnl == 2
+	}
+	red, green, blue := glyphs(desc.Common.RedChnl), glyphs(desc.Common.GreenChnl), glyphs(desc.Common.BlueChnl)
+	switch {
+	case red && !green && !blue:
+		return func(R, G, B uint8) uint8 { return R }
+	case green && !red && !blue:
+		return func(R, G, B uint8) uint8 { return G }
+	case blue && !red && !green:
+		return func(R, G, B uint8) uint8 { return B }
+	}
+	return func(R, G, B uint8) uint8 {
+		return uint8((19595*uint32(R) + 38470*uint32(G) + 7471*uint32(B) + 1<<15) >> 16)
+	}
+}
+
+// loadPage decodes a page. With Coverage set the page is made white with
+// the alpha Coverage reads from the colors of each pixel.
+func loadPage(Page string, OpenPage func(Page string) (io.ReadCloser, error), Coverage func(R, G, B uint8) uint8) (*image.RGBA, error) {
+	in, err := OpenPage(Page)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	decoded, _, err := image.Decode(in)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, fmt.Errorf("page %s: %w: %v", Page, ErrUnsupportedFormat, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("page %s: %w: %v", Page, ErrDecode, err)
+	}
+	bounds := decoded.Bounds()
+	page := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	if Coverage == nil {
+		draw.Draw(page, page.Bounds(), decoded, bounds.Min, draw.Src)
+		return page, nil
+	}
+	// The alpha of the file is unused and may well be 0, so the colors are
+	// read as stored rather than premultiplied by it.
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			var a uint8
+			switch c := decoded.At(bounds.Min.X+x, bounds.Min.Y+y).(type) {
+			case color.NRGBA:
+				a = Coverage(c.R, c.G, c.B)
+			default:
+				r, g, b, _ := c.RGBA()
+				a = Coverage(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+			}
+			i := page.PixOffset(x, y)
+			page.Pix[i], page.Pix[i+1], page.Pix[i+2], page.Pix[i+3] = a, a, a, a
+		}
+	}
+	return page, nil
+}
+
+// parseBMFontText reads the text format: one tag per line followed by
+// key=value pairs, values optionally in double quotes.
+func parseBMFontText(Data []byte, Desc *bmFont) error {
+	scanner := bufio.NewScanner(bytes.NewReader(Data))
+	for line := 1; scanner.Scan(); line++ {
+		tag, pairs, err := splitBMFontLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		ints := func(Keys ...string) []int {
+			values := make([]int, len(Keys))
+			for i, key := range Keys {
+				if value, ok := pairs[key]; ok {
+					n, convErr := strconv.Atoi(value)
+					if convErr != nil && err == nil {
+						err = fmt.Errorf("line %d: %s=%q is not a number", line, key, value)
+					}
+					values[i] = n
+				}
+			}
+			return values
+		}
+		switch tag {
+		case "info":
+			Desc.Info.Size = ints("size")[0]
+		case "common":
+			v := ints("lineHeight", "base", "scaleW", "scaleH", "packed", "alphaChnl", "redChnl", "greenChnl", "blueChnl")
+			Desc.Common.LineHeight, Desc.Common.Base = v[0], v[1]
+			Desc.Common.ScaleW, Desc.Common.ScaleH = v[2], v[3]
+			Desc.Common.Packed, Desc.Common.AlphaChnl = v[4], v[5]
+			Desc.Common.RedChnl, Desc.Common.GreenChnl, Desc.Common.BlueChnl = v[6], v[7], v[8]
+		case "page":
+			Desc.Pages = append(Desc.Pages, struct {
+				Id   int    `xml:"id,attr"`
+				File string `xml:"file,attr"`
+			}{Id: ints("id")[0], File: pairs["file"]})
+		case "char":
+			v := ints("id", "x", "y", "width", "height", "xoffset", "yoffset", "xadvance", "page")
+			Desc.Chars = append(Desc.Chars, struct {
+				Id       rune `xml:"id,attr"`
+				X        int  `xml:"x,attr"`
+				Y        int  `xml:"y,attr"`
+				Width    int  `xml:"width,attr"`
+				Height   int  `xml:"height,attr"`
+				XOffset  int  `xml:"xoffset,attr"`
+				YOffset  int  `xml:"yoffset,attr"`
+				XAdvance int  `xml:"xadvance,attr"`
+				Page     int  `xml:"page,attr"`
+			}{rune(v[0]), v[1], v[2], v[3], v[4], v[5], v[6], v[7], v[8]})
+		case "kerning":
+			v := ints("first", "second", "amount")
+			Desc.Kernings = append(Desc.Kernings, struct {
+				First  rune `xml:"first,attr"`
+				Second rune `xml:"second,attr"`
+				Amount int  `xml:"amount,attr"`
+			}{rune(v[0]), rune(v[1]), v[2]})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// splitBMFontLine splits a line of the text format into its tag and pairs.
+func splitBMFontLine(Line string) (string, map[string]string, error) {
+	Line = strings.TrimSpace(Line)
+	tag, rest, _ := strings.Cut(Line, " ")
+	pairs := map[string]string{}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("%q has no value", rest)
+		}
+		if strings.HasPrefix(value, "\"") {
+			end := strings.IndexByte(value[1:], '"')
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated quote in %q", Line)
+			}
+			pairs[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+		value, rest, _ = strings.Cut(value, " ")
+		pairs[key] = value
+	}
+	return tag, pairs, nil
+}
@@ -0,0 +1,27 @@
+package ttf2atlas_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"path/filepath"
+	"testing"
+)
+
+func TestBMFontTextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "bmfont_text", Width: 128, Height: 96,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			// Both descriptors name the same two pages, A-M on the first and N-~
+			// on the second, and kern AV, VA and To.
+			font, err := app.LoadBMFont(filepath.Join(assets.BMFontDir, "vcr16.fnt"))
+			Golden.Must(t, err)
+			xml, err := app.LoadBMFont(filepath.Join(assets.BMFontDir, "vcr16_xml.fnt"))
+			Golden.Must(t, err)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(4, 4, 16, 0, 0, font, 1, "AVATAR To\nGo 123")
+			app.SetColor(255, 200, 0, 255)
+			app.DrawText(4, 40, 16, 0, 0, xml, 1, "AVATAR To")
+			app.DrawText(4, 56, 32, 0, 0, xml, 1, "Big")
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package ttf2atlas
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"testing/fstest"
+)
+
+// TestPageCoverage loads a two pixel page with the glyphs in different
+// channels and checks the alpha the page gets. The second pixel has an
+// alpha of 0, which a page with alphaChnl=3 may well have.
+func TestPageCoverage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 50, B: 0, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 0, G: 0, B: 255, A: 0})
+	var page bytes.Buffer
+	if err := png.Encode(&page, img); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                    string
+		alpha, red, green, blue int
+		want                    [2]uint8
+	}{
+		{"red", 4, 0, 3, 3, [2]uint8{200, 0}},
+		{"green", 3, 4, 2, 4, [2]uint8{50, 0}},
+		{"blue", 4, 3, 3, 0, [2]uint8{0, 255}},
+		{"luminance", 4, 0, 0, 0, [2]uint8{89, 29}},
+		{"alpha", 0, 4, 4, 4, [2]uint8{255, 0}},
+	}
+	for _, test := range tests {
+		common := fmt.Sprintf("lineHeight=1 base=1 scaleW=2 scaleH=1 pages=1 packed=0 alphaChnl=%d redChnl=%d greenChnl=%d blueChnl=%d",
+			test.alpha, test.red, test.green, test.blue)
+		files := fstest.MapFS{
+			"font.fnt": {Data: []byte("info size=1\ncommon " + common + "\npage id=0 file=\"page.png\"\n")},
+			"page.png": {Data: page.Bytes()},
+		}
+		_, pages, err := LoadBMFontFS(files, "font.fnt")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		got := [2]uint8{pages[0].Pix[3], pages[0].Pix[7]}
+		if got != test.want {
+			t.Errorf("%s: alpha = %v, want %v", test.name, got, test.want)
+		}
+		if test.alpha != 0 && (pages[0].Pix[0] != got[0] || pages[0].Pix[4] != got[1]) {
+			t.Errorf("%s: page is not white on transparent: %v", test.name, pages[0].Pix)
+		}
+	}
+}
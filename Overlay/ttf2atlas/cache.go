@@ -0,0 +1,295 @@
+package ttf2atlas
+
+import (
+	"container/list"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/draw"
+	"os"
+)
+
+// CacheOptions size a GlyphCache. Each page is a PageSize x PageSize image
+// divided into cells as big as the largest glyph of the font plus Padding on
+// every side, none when it is negative; at most MaxPages pages are
+// allocated.
+type CacheOptions struct {
+	PageSize int
+	MaxPages int
+	Padding  int
+}
+
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{PageSize: 512, MaxPages: 4, Padding: 1}
+}
+
+// CacheStats count what a GlyphCache did since it was created. Overflows are
+// glyphs that could not be placed because every cell held a glyph used in the
+// current frame, each counted once per frame.
+type CacheStats struct {
+	Hits, Misses, Evictions, Overflows uint64
+	Glyphs, Pages                      int
+}
+
+// GlyphCache is a Font that rasterizes glyphs the first time they are asked
+// for and keeps them in atlas pages. When the pages are full the least
+// recently used glyph gives its cell to the new one, except glyphs used
+// since the last NextFrame, which the frame being drawn still needs.
+type GlyphCache struct {
+	metrics Metrics
+	opts    CacheOptions
+	data    []byte
+	ttf     *truetype.Font
+	face    font.Face
+
+	cellWidth, cellHeight, cellsPerRow, cellsPerPage int
+
+	pages   []*image.RGBA
+	dirty   map[int]bool
+	free    []cell
+	entries map[rune]*cacheEntry
+	missing map[rune]bool
+	lru     *list.List
+	kerning map[KerningPair]float32
+	frame   uint64
+	stats   CacheStats
+
+	// overflowed are the runes that found no room in the current frame.
+	overflowed map[rune]bool
+}
+
+type cell struct {
+	page, index int
+}
+
+type cacheEntry struct {
+	rune    rune
+	glyph   Glyph
+	cell    cell
+	placed  bool
+	element *list.Element
+	frame   uint64
+}
+
+func NewGlyphCache(FontPath string, FontSize float32, Opts CacheOptions) (*GlyphCache, error) {
+	data, err := os.ReadFile(FontPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewGlyphCacheFromData(data, FontSize, Opts)
+}
+
+// NewGlyphCacheFromData is NewGlyphCache for a font file already in memory.
+func NewGlyphCacheFromData(Data []byte, FontSize float32, Opts CacheOptions) (*GlyphCache, error) {
+	ttf, err := parseFont(Data)
+	if err != nil {
+		return nil, err
+	}
+	face := truetype.NewFace(ttf, &truetype.Options{Size: float64(FontSize)})
+
+	if Opts.Padding < 0 {
+		Opts.Padding = 0
+	}
+	bounds := ttf.Bounds(fixed.Int26_6(FontSize * 64))
+	cellWidth := bounds.Max.X.Ceil() - bounds.Min.X.Floor() + 2*Opts.Padding
+	cellHeight := bounds.Max.Y.Ceil() - bounds.Min.Y.Floor() + 2*Opts.Padding
+	if Opts.PageSize < cellWidth || Opts.PageSize < cellHeight {
+		Opts.PageSize = nextPowerOfTwo(cellWidth)
+		if Opts.PageSize < cellHeight {
+			Opts.PageSize = nextPowerOfTwo(cellHeight)
+		}
+	}
+	if Opts.MaxPages < 1 {
+		Opts.MaxPages = 1
+	}
+
+	cache := &GlyphCache{
+		metrics:      fontMetrics(Data, ttf, face, FontSize),
+		opts:         Opts,
+		data:         Data,
+		ttf:          ttf,
+		face:         face,
+		cellWidth:    cellWidth,
+		cellHeight:   cellHeight,
+		cellsPerRow:  Opts.PageSize / cellWidth,
+		cellsPerPage: (Opts.PageSize / cellWidth) * (Opts.PageSize / cellHeight),
+		dirty:        map[int]bool{},
+		entries:      map[rune]*cacheEntry{},
+		missing:      map[rune]bool{},
+		overflowed:   map[rune]bool{},
+		lru:          list.New(),
+		kerning:      map[KerningPair]float32{},
+	}
+	cache.addPage()
+	return cache, nil
+}
+
+func (cache *GlyphCache) FontMetrics() Metrics {
+	return cache.metrics
+}
+
+// Glyph returns the glyph of Rune, rasterizing it into a page first if it is
+// not cached. A glyph that finds no room is not tried again until NextFrame.
+func (cache *GlyphCache) Glyph(Rune rune) (Glyph, bool) {
+	if entry, ok := cache.entries[Rune]; ok {
+		cache.stats.Hits++
+		cache.touch(entry)
+		return entry.glyph, true
+	}
+	if cache.missing[Rune] || cache.overflowed[Rune] {
+		return Glyph{}, false
+	}
+	cache.stats.Misses++
+
+	glyph, mask, ok := rasterize(cache.ttf, cache.face, Rune)
+	if !ok {
+		cache.missing[Rune] = true
+		return Glyph{}, false
+	}
+	entry := &cacheEntry{rune: Rune, glyph: glyph, frame: cache.frame}
+	if glyph.Width > 0 && glyph.Height > 0 {
+		c, ok := cache.allocate()
+		if !ok {
+			cache.stats.Overflows++
+			cache.overflowed[Rune] = true
+			return Glyph{}, false
+		}
+		entry.cell = c
+		entry.placed = true
+		entry.element = cache.lru.PushFront(entry)
+		entry.glyph.Page = c.page
+		entry.glyph.X = (c.index%cache.cellsPerRow)*cache.cellWidth + cache.opts.Padding
+		entry.glyph.Y = (c.index/cache.cellsPerRow)*cache.cellHeight + cache.opts.Padding
+		entry.glyph.setUV(cache.opts.PageSize, cache.opts.PageSize)
+		drawGlyph(cache.pages[c.page], entry.glyph, mask)
+		cache.dirty[c.page] = true
+	}
+	cache.entries[Rune] = entry
+	return entry.glyph, true
+}
+
+// Has reports whether the font has a glyph for Rune. Unlike Glyph it is
+// also true for a glyph the pages have no room for in the current frame.
+func (cache *GlyphCache) Has(Rune rune) bool {
+	if _, ok := cache.entries[Rune]; ok {
+		return true
+	}
+	return !cache.missing[Rune] && cache.ttf.Index(Rune) != 0
+}
+
+// Kern looks the pair up in the font the first time it is asked for.
+func (cache *GlyphCache) Kern(Left, Right rune) float32 {
+	pair := KerningPair{Left: Left, Right: Right}
+	if value, ok := cache.kerning[pair]; ok {
+		return value
+	}
+	left, right := cache.ttf.Index(Left), cache.ttf.Index(Right)
+	if left == 0 || right == 0 {
+		return 0
+	}
+	indices := map[truetype.Index][]rune{left: {Left}}
+	indices[right] = append(indices[right], Right)
+	value := kerning(cache.data, cache.ttf, cache.metrics.FontSize, indices)[pair]
+	cache.kerning[pair] = value
+	return value
+}
+
+// NextFrame starts a new frame: glyphs used before it may be evicted again,
+// and glyphs that found no room are tried again.
+func (cache *GlyphCache) NextFrame() {
+	cache.frame++
+	if len(cache.overflowed) > 0 {
+		cache.overflowed = map[rune]bool{}
+	}
+}
+
+// PageCount is MaxPages, the pages a cache may grow to.
+func (cache *GlyphCache) PageCount() int {
+	return cache.opts.MaxPages
+}
+
+// Pages returns the atlas images, a glyph's Page indexes into them.
+func (cache *GlyphCache) Pages() []*image.RGBA {
+	return cache.pages
+}
+
+// TakeDirtyPages returns the pages changed since the last call, in order,
+// so the caller can upload them again.
+func (cache *GlyphCache) TakeDirtyPages() []int {
+	var pages []int
+	for page := range cache.pages {
+		if cache.dirty[page] {
+			pages = append(pages, page)
+		}
+	}
+	cache.dirty = map[int]bool{}
+	return pages
+}
+
+// MarkDirty makes TakeDirtyPages return Page again, for a caller that
+// could not upload it.
+func (cache *GlyphCache) MarkDirty(Page int) {
+	if Page >= 0 && Page < len(cache.pages) {
+		cache.dirty[Page] = true
+	}
+}
+
+func (cache *GlyphCache) Stats() CacheStats {
+	stats := cache.stats
+	stats.Glyphs = len(cache.entries)
+	stats.Pages = len(cache.pages)
+	return stats
+}
+
+func (cache *GlyphCache) Close() error {
+	return cache.face.Close()
+}
+
+func (cache *GlyphCache) touch(entry *cacheEntry) {
+	entry.frame = cache.frame
+	if entry.placed {
+		cache.lru.MoveToFront(entry.element)
+	}
+}
+
+func (cache *GlyphCache) addPage() {
+	page := len(cache.pages)
+	cache.pages = append(cache.pages, image.NewRGBA(image.Rect(0, 0, cache.opts.PageSize, cache.opts.PageSize)))
+	for i := cache.cellsPerPage - 1; i >= 0; i-- {
+		cache.free = append(cache.free, cell{page: page, index: i})
+	}
+	cache.dirty[page] = true
+}
+
+// allocate returns a free cell, growing a page or evicting the least
+// recently used glyph when there is none.
+func (cache *GlyphCache) allocate() (cell, bool) {
+	if len(cache.free) == 0 && len(cache.pages) < cache.opts.MaxPages {
+		cache.addPage()
+	}
+	if n := len(cache.free); n > 0 {
+		c := cache.free[n-1]
+		cache.free = cache.free[:n-1]
+		return c, true
+	}
+
+	back := cache.lru.Back()
+	if back == nil {
+		return cell{}, false
+	}
+	entry := back.Value.(*cacheEntry)
+	if entry.frame == cache.frame {
+		return cell{}, false
+	}
+	cache.lru.Remove(back)
+	delete(cache.entries, entry.rune)
+	cache.stats.Evictions++
+
+	x := (entry.cell.index % cache.cellsPerRow) * cache.cellWidth
+	y := (entry.cell.index / cache.cellsPerRow) * cache.cellHeight
+	rect := image.Rect(x, y, x+cache.cellWidth, y+cache.cellHeight)
+	draw.Draw(cache.pages[entry.cell.page], rect, image.Transparent, image.Point{}, draw.Src)
+	cache.dirty[entry.cell.page] = true
+	return entry.cell, true
+}
@@ -0,0 +1,20 @@
+package ttf2atlas_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"DrawerGO/Overlay/ttf2atlas"
+	"testing"
+)
+
+func TestDynamicTextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "dynamic_text", Width: 128, Height: 48,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			font, err := app.LoadDynamicFontWithOptions(assets.FontPath, 16, ttf2atlas.CacheOptions{PageSize: 64, MaxPages: 2, Padding: 1})
+			Golden.Must(t, err)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(4, 4, 16, 0, 0, font, 1, "Overlay\nGo 123")
+		},
+	})
+}
@@ -0,0 +1,157 @@
+package ttf2atlas
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestOverflowIsNotMissing fills a one page cache within a frame and checks
+// the glyphs without room are not reported missing, and are drawn once the
+// next frame can evict.
+func TestOverflowIsNotMissing(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewGlyphCacheFromData(data, 16, CacheOptions{PageSize: 64, MaxPages: 1, Padding: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+
+	var text []rune
+	for r := 'A'; len(text) <= cache.cellsPerPage; r++ {
+		text = append(text, r)
+	}
+	last := text[len(text)-1]
+	missing := MissingRunes(cache, string(text)+"\u4e00")
+	if cache.Stats().Overflows == 0 {
+		t.Fatalf("%d glyphs fit into %d cells", len(text), cache.cellsPerPage)
+	}
+	if want := []rune{0x4e00}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %U, want %U", missing, want)
+	}
+	if _, ok := cache.Glyph(last); ok {
+		t.Errorf("%c got a cell in a full frame", last)
+	}
+	if !cache.Has(last) || cache.Has(0x4e00) {
+		t.Errorf("Has(%c) = %v, Has(U+4E00) = %v", last, cache.Has(last), cache.Has(0x4e00))
+	}
+
+	cache.NextFrame()
+	if _, ok := cache.Glyph(last); !ok {
+		t.Errorf("%c has no glyph in the next frame", last)
+	}
+	if family := NewFamily(cache); !family.Has(last) || family.Has(0x4e00) {
+		t.Errorf("family Has(%c) = %v, Has(U+4E00) = %v", last, family.Has(last), family.Has(0x4e00))
+	}
+}
+
+// fullCache returns a one page cache filled within the current frame, and
+// the first rune that found no room.
+func fullCache(t *testing.T) (*GlyphCache, rune) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewGlyphCacheFromData(data, 16, CacheOptions{PageSize: 64, MaxPages: 1, Padding: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r := 'A'; r <= 'z'; r++ {
+		if _, ok := cache.Glyph(r); !ok {
+			return cache, r
+		}
+	}
+	cache.Close()
+	t.Fatal("the cache did not overflow")
+	return nil, 0
+}
+
+func TestOverflowOncePerFrame(t *testing.T) {
+	cache, last := fullCache(t)
+	defer cache.Close()
+	before := cache.Stats()
+	for i := 0; i < 3; i++ {
+		if _, ok := cache.Glyph(last); ok {
+			t.Fatalf("%c got a cell in a full frame", last)
+		}
+	}
+	if after := cache.Stats(); after.Misses != before.Misses || after.Overflows != before.Overflows || after.Overflows != 1 {
+		t.Errorf("stats went from %+v to %+v, want one overflow and no more misses", before, after)
+	}
+	cache.NextFrame()
+	if _, ok := cache.Glyph(last); !ok {
+		t.Errorf("%c has no glyph in the next frame", last)
+	}
+}
+
+func TestFamilyKeepsOverflowedRunes(t *testing.T) {
+	cache, last := fullCache(t)
+	defer cache.Close()
+	fallback := &BitmapFont{
+		Metrics: cache.FontMetrics(),
+		Pages:   []string{"page.png"},
+		Glyphs: map[rune]Glyph{
+			last:   {Width: 4, Height: 4, Advance: 5},
+			0x4e00: {Width: 8, Height: 8, Advance: 9},
+		},
+	}
+	family := NewFamily(cache, fallback)
+
+	if _, ok := family.Glyph(last); ok {
+		t.Errorf("%c taken from the fallback while the cache has no room", last)
+	}
+	if !family.Has(last) {
+		t.Errorf("family Has(%c) = false", last)
+	}
+	if glyph, ok := family.Glyph(0x4e00); !ok || glyph.Page != cache.PageCount() || glyph.Advance != 9 {
+		t.Errorf("U+4E00 = %+v %v, want the glyph of the fallback", glyph, ok)
+	}
+
+	hits := cache.Stats().Hits
+	if _, ok := family.Glyph('A'); !ok {
+		t.Fatal("A has no glyph")
+	}
+	if calls := cache.Stats().Hits - hits; calls != 1 {
+		t.Errorf("family asked the cache for A %d times, want once", calls)
+	}
+
+	cache.NextFrame()
+	if glyph, ok := family.Glyph(last); !ok || glyph.Page != 0 || glyph.Advance == 5 {
+		t.Errorf("%c = %+v %v in the next frame, want the glyph of the cache", last, glyph, ok)
+	}
+}
+
+func TestNegativeCachePadding(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewGlyphCacheFromData(data, 16, CacheOptions{PageSize: 128, MaxPages: 1, Padding: -3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	var rects []image.Rectangle
+	for r := 'A'; r <= 'Z'; r++ {
+		glyph, ok := cache.Glyph(r)
+		if !ok {
+			t.Fatalf("no glyph for %c", r)
+		}
+		rect := image.Rect(glyph.X, glyph.Y, glyph.X+glyph.Width, glyph.Y+glyph.Height)
+		if !rect.In(image.Rect(0, 0, 128, 128)) {
+			t.Fatalf("%c at %v is outside of the page", r, rect)
+		}
+		for _, other := range rects {
+			if rect.Overlaps(other) {
+				t.Fatalf("%c at %v overlaps %v", r, rect, other)
+			}
+		}
+		rects = append(rects, rect)
+	}
+}
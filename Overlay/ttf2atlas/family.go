@@ -0,0 +1,174 @@
+package ttf2atlas
+
+import (
+	"DrawerGO/Overlay/Shape"
+	"sort"
+	"unicode"
+)
+
+// Family is a Font that takes every glyph from the first of its Fonts that
+// has it, so runes missing in the first font fall back to the next one. The
+// layout uses the metrics of the first font; glyphs of fonts rasterized at
+// another FontSize are scaled to it. A glyph cache that has a rune but no
+// room for it in the current frame keeps it: the rune is left out of that
+// frame, not drawn from a later font.
+//
+// The pages of the members follow each other: the first font owns pages
+// 0..PageCount()-1, the second the ones after that and so on.
+type Family struct {
+	Fonts []Font
+}
+
+func NewFamily(Fonts ...Font) *Family {
+	return &Family{Fonts: Fonts}
+}
+
+func (family *Family) FontMetrics() Metrics {
+	if len(family.Fonts) == 0 {
+		return Metrics{}
+	}
+	return family.Fonts[0].FontMetrics()
+}
+
+func (family *Family) Glyph(Rune rune) (Glyph, bool) {
+	member, page, glyph, ok := family.find(Rune)
+	if !ok {
+		return Glyph{}, false
+	}
+	glyph.Page += page
+	if scale := family.scale(member); scale != 1 {
+		glyph.BearingX *= scale
+		glyph.BearingY *= scale
+		glyph.Advance *= scale
+		if glyph.Scale == 0 {
+			glyph.Scale = 1
+		}
+		glyph.Scale *= scale
+	}
+	return glyph, true
+}
+
+// Has reports whether a font of the family has a glyph for Rune, see
+// GlyphCache.Has.
+func (family *Family) Has(Rune rune) bool {
+	for _, member := range family.Fonts {
+		if has(member, Rune) {
+			return true
+		}
+	}
+	return false
+}
+
+// Kern kerns two runes taken from the same font; there is no kerning between
+// glyphs of different fonts.
+func (family *Family) Kern(Left, Right rune) float32 {
+	left, _, _, ok := family.find(Left)
+	if !ok {
+		return 0
+	}
+	right, _, _, ok := family.find(Right)
+	if !ok || left != right {
+		return 0
+	}
+	return family.Fonts[left].Kern(Left, Right) * family.scale(left)
+}
+
+func (family *Family) PageCount() int {
+	count := 0
+	for _, member := range family.Fonts {
+		count += member.PageCount()
+	}
+	return count
+}
+
+// Member returns the font that owns the family page Page and the index of
+// that page within it.
+func (family *Family) Member(Page int) (Font, int, bool) {
+	for _, member := range family.Fonts {
+		if Page < member.PageCount() {
+			return member, Page, true
+		}
+		Page -= member.PageCount()
+	}
+	return nil, 0, false
+}
+
+// find returns the index of the first font that has Rune, the first family
+// page of that font and the glyph of Rune in it. A font that has Rune but no
+// room for it in the current frame ends the search, so the glyph is left out
+// of this frame instead of being drawn from another font.
+func (family *Family) find(Rune rune) (int, int, Glyph, bool) {
+	page := 0
+	for i, member := range family.Fonts {
+		if glyph, ok := member.Glyph(Rune); ok {
+			return i, page, glyph, true
+		}
+		if overflowed(member, Rune) {
+			return 0, 0, Glyph{}, false
+		}
+		page += member.PageCount()
+	}
+	return 0, 0, Glyph{}, false
+}
+
+func (family *Family) scale(Member int) float32 {
+	size := family.Fonts[Member].FontMetrics().FontSize
+	if Member == 0 || size == 0 {
+		return 1
+	}
+	return family.Fonts[0].FontMetrics().FontSize / size
+}
+
+// MissingRunes returns the runes of Text that Font cannot draw, sorted and
+// without duplicates. Text is read in grapheme clusters the way layout reads
+// it: a mark composed into a precomposed glyph is not missing, and joiners
+// and selectors never are. Tabs, line breaks and other control characters
+// are not drawn as glyphs and never count as missing, nor do glyphs a
+// GlyphCache has no room for in the current frame.
+func MissingRunes(Font Font, Text string) []rune {
+	set := map[rune]bool{}
+	for _, cluster := range Shape.Clusters(Text) {
+		if unicode.IsControl(cluster.Runes[0]) {
+			continue
+		}
+		base, marks := cluster.Resolve(func(r rune) bool { return has(Font, r) })
+		for _, r := range append([]rune{base}, marks...) {
+			if !set[r] && !has(Font, r) {
+				set[r] = true
+			}
+		}
+	}
+	return sortedRunes(set)
+}
+
+// has reports whether Font has a glyph for Rune. Glyph is asked first, so a
+// glyph cache rasterizes the glyph; one it has no room for is not missing.
+func has(Font Font, Rune rune) bool {
+	if _, ok := Font.Glyph(Rune); ok {
+		return true
+	}
+	return overflowed(Font, Rune)
+}
+
+// overflowed reports whether Font, whose Glyph just returned false for
+// Rune, has the glyph but no room for it in the current frame.
+func overflowed(Font Font, Rune rune) bool {
+	switch font := Font.(type) {
+	case *GlyphCache:
+		return font.Has(Rune)
+	case *Family:
+		return font.Has(Rune)
+	}
+	return false
+}
+
+func sortedRunes(Set map[rune]bool) []rune {
+	runes := make([]rune, 0, len(Set))
+	for r := range Set {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool {
+		return runes[i] < runes[j]
+	})
+	return runes
+}
@@ -0,0 +1,25 @@
+package ttf2atlas_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"DrawerGO/Overlay/ttf2atlas"
+	"testing"
+)
+
+func TestFontFamilyGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "font_family", Width: 128, Height: 48,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			// The primary font only has capitals, the rest comes from the same
+			// font at twice the size, scaled down to match.
+			capitals, err := app.LoadFontWithOptions(assets.FontPath, 16, ttf2atlas.Options{Ranges: []ttf2atlas.Range{{First: 'A', Last: 'Z'}}, Padding: 1})
+			Golden.Must(t, err)
+			fallback, err := app.LoadFontWithOptions(assets.FontPath, 32, ttf2atlas.DefaultOptions())
+			Golden.Must(t, err)
+			font := app.NewFontFamily(capitals, fallback)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(4, 4, 16, 0, 0, font, 1, "Overlay\nGo 123")
+		},
+	})
+}
@@ -0,0 +1,288 @@
+package ttf2atlas
+
+import (
+	"encoding/binary"
+	"github.com/golang/freetype/truetype"
+)
+
+// KerningPair is two runes drawn next to each other, Left first.
+type KerningPair struct {
+	Left, Right rune
+}
+
+/*
+
+Kerning is read straight from the font file: the old kern table and the
+pair adjustment lookups of the kern feature in GPOS, which is where most
+current fonts keep it. Malformed tables are skipped, the atlas is still
+usable without kerning.
+
+*/
+
+// table returns the bytes of the table called Tag, or nil.
+func table(Data []byte, Tag string) []byte {
+	if len(Data) < 12 {
+		return nil
+	}
+	tables := int(binary.BigEndian.Uint16(Data[4:]))
+	for i := 0; i < tables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(Data) {
+			return nil
+		}
+		if string(Data[record:record+4]) != Tag {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(Data[record+8:]))
+		length := int(binary.BigEndian.Uint32(Data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(Data) {
+			return nil
+		}
+		return Data[offset : offset+length]
+	}
+	return nil
+}
+
+// reader reads big endian values and remembers if it ever read out of range,
+// so the parsers below can check once instead of after every read.
+type reader struct {
+	data []byte
+	bad  bool
+}
+
+func (r *reader) u16(Offset int) int {
+	if Offset < 0 || Offset+2 > len(r.data) {
+		r.bad = true
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(r.data[Offset:]))
+}
+func (r *reader) i16(Offset int) int {
+	return int(int16(r.u16(Offset)))
+}
+func (r *reader) tag(Offset int) string {
+	if Offset < 0 || Offset+4 > len(r.data) {
+		r.bad = true
+		return ""
+	}
+	return string(r.data[Offset : Offset+4])
+}
+func (r *reader) u32(Offset int) int {
+	if Offset < 0 || Offset+4 > len(r.data) {
+		r.bad = true
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(r.data[Offset:]))
+}
+
+// kerning collects the pair adjustments between the runes of Glyphs, keyed
+// by glyph index, scaled to FontSize pixels.
+func kerning(Data []byte, Font *truetype.Font, FontSize float32, Glyphs map[truetype.Index][]rune) map[KerningPair]float32 {
+	units := map[[2]truetype.Index]int{}
+	gposKerning(&reader{data: table(Data, "GPOS")}, Glyphs, units)
+	kernKerning(&reader{data: table(Data, "kern")}, Glyphs, units)
+
+	scale := FontSize / float32(Font.FUnitsPerEm())
+	pairs := map[KerningPair]float32{}
+	for key, value := range units {
+		for _, left := range Glyphs[key[0]] {
+			for _, right := range Glyphs[key[1]] {
+				pairs[KerningPair{Left: left, Right: right}] = float32(value) * scale
+			}
+		}
+	}
+	return pairs
+}
+
+// set stores a pair unless an earlier table already did.
+func set(Units map[[2]truetype.Index]int, Left, Right, Value int) {
+	key := [2]truetype.Index{truetype.Index(Left), truetype.Index(Right)}
+	if _, ok := Units[key]; ok || Value == 0 {
+		return
+	}
+	Units[key] = Value
+}
+
+// kernKerning reads the horizontal format 0 subtables of the kern table.
+func kernKerning(r *reader, Glyphs map[truetype.Index][]rune, Units map[[2]truetype.Index]int) {
+	if len(r.data) == 0 {
+		return
+	}
+	offset := 4
+	for n := r.u16(2); n > 0 && !r.bad; n-- {
+		length := r.u16(offset + 2)
+		coverage := r.u16(offset + 4)
+		if coverage>>8 == 0 && coverage&0x7 == 1 {
+			pairs := r.u16(offset + 6)
+			for i := 0; i < pairs && !r.bad; i++ {
+				record := offset + 14 + 6*i
+				left, right := r.u16(record), r.u16(record+2)
+				if Glyphs[truetype.Index(left)] != nil && Glyphs[truetype.Index(right)] != nil {
+					set(Units, left, right, r.i16(record+4))
+				}
+			}
+		}
+		if length == 0 {
+			return
+		}
+		offset += length
+	}
+}
+
+// gposKerning reads the pair adjustment lookups of the kern feature.
+func gposKerning(r *reader, Glyphs map[truetype.Index][]rune, Units map[[2]truetype.Index]int) {
+	if len(r.data) == 0 {
+		return
+	}
+	features := r.u16(6)
+	lookups := r.u16(8)
+	seen := map[int]bool{}
+	for i := 0; i < r.u16(features) && !r.bad; i++ {
+		record := features + 2 + 6*i
+		if r.tag(record) != "kern" {
+			continue
+		}
+		feature := features + r.u16(record+4)
+		for j := 0; j < r.u16(feature+2) && !r.bad; j++ {
+			index := r.u16(feature + 4 + 2*j)
+			if seen[index] {
+				continue
+			}
+			seen[index] = true
+			lookup := lookups + r.u16(lookups+2+2*index)
+			kind := r.u16(lookup)
+			for k := 0; k < r.u16(lookup+4) && !r.bad; k++ {
+				subtable := lookup + r.u16(lookup+6+2*k)
+				if kind == 9 && r.u16(subtable+2) == 2 {
+					pairPos(r, subtable+r.u32(subtable+4), Glyphs, Units)
+				} else if kind == 2 {
+					pairPos(r, subtable, Glyphs, Units)
+				}
+			}
+		}
+	}
+}
+
+// pairPos reads a PairPos subtable, format 1 (glyph pairs) or format 2
+// (class pairs), keeping the XAdvance of the first glyph.
+func pairPos(r *reader, Offset int, Glyphs map[truetype.Index][]rune, Units map[[2]truetype.Index]int) {
+	format := r.u16(Offset)
+	coverage := Offset + r.u16(Offset+2)
+	format1, format2 := r.u16(Offset+4), r.u16(Offset+6)
+	if format1&0x4 == 0 {
+		return
+	}
+	advance := 2 * bits(format1&0x3)
+	size := 2*bits(format1) + 2*bits(format2)
+
+	switch format {
+	case 1:
+		sets := r.u16(Offset + 8)
+		for glyph := range Glyphs {
+			i := coverageIndex(r, coverage, int(glyph))
+			if i < 0 || i >= sets {
+				continue
+			}
+			pairSet := Offset + r.u16(Offset+10+2*i)
+			for j := 0; j < r.u16(pairSet) && !r.bad; j++ {
+				record := pairSet + 2 + j*(2+size)
+				second := r.u16(record)
+				if Glyphs[truetype.Index(second)] != nil {
+					set(Units, int(glyph), second, r.i16(record+2+advance))
+				}
+			}
+		}
+	case 2:
+		classDef1 := Offset + r.u16(Offset+8)
+		classDef2 := Offset + r.u16(Offset+10)
+		class1Count, class2Count := r.u16(Offset+12), r.u16(Offset+14)
+		seconds := map[int][]int{}
+		for glyph := range Glyphs {
+			class := glyphClass(r, classDef2, int(glyph))
+			seconds[class] = append(seconds[class], int(glyph))
+		}
+		for glyph := range Glyphs {
+			if coverageIndex(r, coverage, int(glyph)) < 0 {
+				continue
+			}
+			class1 := glyphClass(r, classDef1, int(glyph))
+			if class1 >= class1Count {
+				continue
+			}
+			for class2, glyphs := range seconds {
+				if class2 >= class2Count {
+					continue
+				}
+				record := Offset + 16 + (class1*class2Count+class2)*size
+				value := r.i16(record + advance)
+				for _, second := range glyphs {
+					set(Units, int(glyph), second, value)
+				}
+			}
+		}
+	}
+}
+
+// coverageIndex returns the coverage index of Glyph, or -1. Both coverage
+// formats are sorted by glyph, so they are searched by bisection.
+func coverageIndex(r *reader, Offset, Glyph int) int {
+	format := r.u16(Offset)
+	low, high := 0, r.u16(Offset+2)
+	for low < high && !r.bad {
+		i := (low + high) / 2
+		switch format {
+		case 1:
+			glyph := r.u16(Offset + 4 + 2*i)
+			if glyph == Glyph {
+				return i
+			}
+			if glyph < Glyph {
+				low = i + 1
+			} else {
+				high = i
+			}
+		case 2:
+			record := Offset + 4 + 6*i
+			start, end := r.u16(record), r.u16(record+2)
+			if Glyph >= start && Glyph <= end {
+				return r.u16(record+4) + Glyph - start
+			}
+			if end < Glyph {
+				low = i + 1
+			} else {
+				high = i
+			}
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// glyphClass returns the class of Glyph in a ClassDef table, 0 when it is
+// not listed.
+func glyphClass(r *reader, Offset, Glyph int) int {
+	switch r.u16(Offset) {
+	case 1:
+		start := r.u16(Offset + 2)
+		if Glyph >= start && Glyph < start+r.u16(Offset+4) {
+			return r.u16(Offset + 6 + 2*(Glyph-start))
+		}
+	case 2:
+		for i := 0; i < r.u16(Offset+2) && !r.bad; i++ {
+			record := Offset + 4 + 6*i
+			if Glyph >= r.u16(record) && Glyph <= r.u16(record+2) {
+				return r.u16(record + 4)
+			}
+		}
+	}
+	return 0
+}
+
+func bits(v int) int {
+	n := 0
+	for ; v != 0; v &= v - 1 {
+		n++
+	}
+	return n
+}
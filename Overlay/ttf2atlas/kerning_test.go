@@ -0,0 +1,191 @@
+package ttf2atlas
+
+import (
+	"encoding/binary"
+	"github.com/golang/freetype/truetype"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The tables below are built by hand; offsets are taken from the lengths
+// of the parts before them.
+
+func u16s(Values ...int) []byte {
+	out := make([]byte, 0, 2*len(Values))
+	for _, v := range Values {
+		out = binary.BigEndian.AppendUint16(out, uint16(v))
+	}
+	return out
+}
+
+func cat(Parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range Parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// kernTable is a kern table with one horizontal format 0 subtable holding
+// Pairs, each a left glyph, a right glyph and a value.
+func kernTable(Pairs ...[3]int) []byte {
+	var records []byte
+	for _, pair := range Pairs {
+		records = append(records, u16s(pair[0], pair[1], pair[2])...)
+	}
+	subtable := cat(u16s(0, 14+len(records), 0x0001, len(Pairs), 0, 0, 0), records)
+	return cat(u16s(0, 1), subtable)
+}
+
+// pairPosFormat1 kerns First before Second by Value.
+func pairPosFormat1(First, Second, Value int) []byte {
+	pairSet := u16s(1, Second, Value)
+	coverage := u16s(1, 1, First)
+	header := 12
+	return cat(u16s(1, header+len(pairSet), 0x4, 0, 1, header), pairSet, coverage)
+}
+
+// pairPosFormat2 kerns First before Second by Value, through a class of
+// each.
+func pairPosFormat2(First, Second, Value int) []byte {
+	records := u16s(0, 0, 0, Value)
+	classDef1 := u16s(1, First, 1, 1)
+	classDef2 := u16s(2, 1, Second, Second, 1)
+	coverage := u16s(2, 1, First, First, 0)
+	header := 16
+	classDef1At := header + len(records)
+	classDef2At := classDef1At + len(classDef1)
+	coverageAt := classDef2At + len(classDef2)
+	return cat(u16s(2, coverageAt, 0x4, 0, classDef1At, classDef2At, 2, 2), records, classDef1, classDef2, coverage)
+}
+
+// extension wraps a PairPos subtable in an extension subtable.
+func extension(Subtable []byte) []byte {
+	return cat(u16s(1, 2), binary.BigEndian.AppendUint32(nil, 8), Subtable)
+}
+
+// lookup is a GPOS lookup of type Kind with a single subtable.
+type lookup struct {
+	Kind     int
+	Subtable []byte
+}
+
+// gposTable is a GPOS table whose kern feature uses all of Lookups.
+func gposTable(Lookups ...lookup) []byte {
+	feature := u16s(0, len(Lookups))
+	for i := range Lookups {
+		feature = append(feature, u16s(i)...)
+	}
+	featureList := cat(u16s(1), []byte("kern"), u16s(8), feature)
+
+	lookupList := u16s(len(Lookups))
+	var lookups []byte
+	at := 2 + 2*len(Lookups)
+	for _, lookup := range Lookups {
+		lookupList = append(lookupList, u16s(at+len(lookups))...)
+		lookups = append(lookups, u16s(lookup.Kind, 0, 1, 8)...)
+		lookups = append(lookups, lookup.Subtable...)
+	}
+	header := 10
+	return cat(
+		binary.BigEndian.AppendUint32(nil, 0x00010000),
+		u16s(0, header, header+len(featureList)),
+		featureList, lookupList, lookups,
+	)
+}
+
+func TestKernTable(t *testing.T) {
+	glyphs := map[truetype.Index][]rune{1: {'A'}, 2: {'V'}, 3: {'T'}}
+	units := map[[2]truetype.Index]int{}
+	kernKerning(&reader{data: kernTable([3]int{1, 2, -80}, [3]int{2, 1, -40}, [3]int{1, 9, -10})}, glyphs, units)
+
+	want := map[[2]truetype.Index]int{{1, 2}: -80, {2, 1}: -40}
+	if len(units) != len(want) {
+		t.Fatalf("pairs = %v, want %v", units, want)
+	}
+	for key, value := range want {
+		if units[key] != value {
+			t.Errorf("pair %v = %d, want %d", key, units[key], value)
+		}
+	}
+}
+
+func TestGposTable(t *testing.T) {
+	glyphs := map[truetype.Index][]rune{1: {'A'}, 2: {'V'}, 3: {'T'}, 4: {'o'}}
+	gpos := gposTable(
+		lookup{2, pairPosFormat1(1, 2, -80)},
+		lookup{9, extension(pairPosFormat2(3, 4, -120))},
+	)
+	units := map[[2]truetype.Index]int{}
+	gposKerning(&reader{data: gpos}, glyphs, units)
+	// The kern table only adds pairs GPOS does not have.
+	kernKerning(&reader{data: kernTable([3]int{1, 2, -50}, [3]int{2, 1, -40})}, glyphs, units)
+
+	want := map[[2]truetype.Index]int{{1, 2}: -80, {3, 4}: -120, {2, 1}: -40}
+	if len(units) != len(want) {
+		t.Fatalf("pairs = %v, want %v", units, want)
+	}
+	for key, value := range want {
+		if units[key] != value {
+			t.Errorf("pair %v = %d, want %d", key, units[key], value)
+		}
+	}
+}
+
+func TestMalformedTables(t *testing.T) {
+	glyphs := map[truetype.Index][]rune{1: {'A'}, 2: {'V'}}
+	kern := kernTable([3]int{1, 2, -80})
+	gpos := gposTable(lookup{2, pairPosFormat1(1, 2, -80)})
+	for n := 0; n < len(kern); n++ {
+		kernKerning(&reader{data: kern[:n]}, glyphs, map[[2]truetype.Index]int{})
+	}
+	for n := 0; n < len(gpos); n++ {
+		gposKerning(&reader{data: gpos[:n]}, glyphs, map[[2]truetype.Index]int{})
+	}
+}
+
+// withTable returns the font file Data with the table Tag added.
+func withTable(Data []byte, Tag string, Table []byte) []byte {
+	tables := int(binary.BigEndian.Uint16(Data[4:]))
+	directory := 12 + 16*tables
+	out := append([]byte{}, Data[:directory]...)
+	binary.BigEndian.PutUint16(out[4:], uint16(tables+1))
+	for i := 0; i < tables; i++ {
+		record := out[12+16*i:]
+		binary.BigEndian.PutUint32(record[8:], binary.BigEndian.Uint32(record[8:])+16)
+	}
+	body := append([]byte{}, Data[directory:]...)
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	out = append(out, Tag...)
+	out = binary.BigEndian.AppendUint32(out, 0)
+	out = binary.BigEndian.AppendUint32(out, uint32(directory+16+len(body)))
+	out = binary.BigEndian.AppendUint32(out, uint32(len(Table)))
+	return cat(out, body, Table)
+}
+
+func TestAtlasKerning(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ttf, err := truetype.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, v := int(ttf.Index('A')), int(ttf.Index('V'))
+	data = withTable(data, "kern", kernTable([3]int{a, v, -int(ttf.FUnitsPerEm()) / 8}))
+
+	_, atlas, err := FontDataToAtlas(data, 16, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kern := atlas.Kern('A', 'V'); kern != -2 {
+		t.Errorf("Kern(A, V) = %g, want -2", kern)
+	}
+	if kern := atlas.Kern('V', 'A'); kern != 0 {
+		t.Errorf("Kern(V, A) = %g, want 0", kern)
+	}
+}
@@ -0,0 +1,87 @@
+package ttf2atlas
+
+import (
+	"image"
+	"sort"
+)
+
+// shelfPacker places rectangles left to right on horizontal shelves. A
+// rectangle goes on the first shelf that is tall enough and has room left,
+// otherwise a new shelf is opened below the last one.
+type shelfPacker struct {
+	width, height int
+	shelves       []shelf
+}
+
+type shelf struct {
+	y, height, used int
+}
+
+// pack returns where a Width x Height rectangle goes, or false when it does
+// not fit any more.
+func (p *shelfPacker) pack(Width, Height int) (image.Point, bool) {
+	if Width > p.width {
+		return image.Point{}, false
+	}
+	for i := range p.shelves {
+		s := &p.shelves[i]
+		if Height <= s.height && s.used+Width <= p.width {
+			at := image.Point{X: s.used, Y: s.y}
+			s.used += Width
+			return at, true
+		}
+	}
+	y := p.bottom()
+	if y+Height > p.height {
+		return image.Point{}, false
+	}
+	p.shelves = append(p.shelves, shelf{y: y, height: Height, used: Width})
+	return image.Point{X: 0, Y: y}, true
+}
+
+// packAll finds the narrowest power of two width at which every size packs
+// into a height no larger than the width, and returns that width, the packed
+// height rounded up to a power of two and the position of each size. Sizes
+// are placed tallest first, which keeps shelves tight.
+func packAll(Sizes []image.Point) (int, int, []image.Point) {
+	order := make([]int, len(Sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return Sizes[order[a]].Y > Sizes[order[b]].Y
+	})
+
+	positions := make([]image.Point, len(Sizes))
+	for width := 1; ; width *= 2 {
+		packer := shelfPacker{width: width, height: width}
+		fits := true
+		for _, i := range order {
+			at, ok := packer.pack(Sizes[i].X, Sizes[i].Y)
+			if !ok {
+				fits = false
+				break
+			}
+			positions[i] = at
+		}
+		if fits {
+			return width, nextPowerOfTwo(packer.bottom()), positions
+		}
+	}
+}
+
+// bottom is the lowest edge of any shelf.
+func (p *shelfPacker) bottom() int {
+	if n := len(p.shelves); n > 0 {
+		return p.shelves[n-1].y + p.shelves[n-1].height
+	}
+	return 0
+}
+
+func nextPowerOfTwo(v int) int {
+	n := 1
+	for n < v {
+		n *= 2
+	}
+	return n
+}
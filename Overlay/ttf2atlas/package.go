@@ -1,51 +1,184 @@
 package ttf2atlas
 
 import (
+	"errors"
+	"fmt"
 	"github.com/golang/freetype"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
 	"image"
+	"image/draw"
+	"math"
 	"os"
 )
 
-const defaultSymbolsPerWidth int = 32
-const defaultSymbolsCount int = 2048
+var (
+	// ErrDecode is wrapped by the errors of files that could not be parsed.
+	ErrDecode = errors.New("decode failed")
+	// ErrUnsupportedFormat is wrapped by the errors of files in a format, or
+	// using a feature of one, that is not supported.
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	// ErrInvalidRange is wrapped by the errors of Options holding a Range
+	// that starts after its end or outside Unicode.
+	ErrInvalidRange = errors.New("invalid range")
+)
 
-var symbolsPerWidth = defaultSymbolsPerWidth
-var symbolsCount = defaultSymbolsCount
+// FontToAtlas builds an atlas of DefaultOptions.
+func FontToAtlas(FontPath string, FontSize float32) (*image.RGBA, *FontAtlas, error) {
+	return FontToAtlasWithOptions(FontPath, FontSize, DefaultOptions())
+}
 
-func SetSymbolsPerWidth(PerWidth int) { symbolsPerWidth = PerWidth }
-func SetDefaultSymbolsPerWidth()      { symbolsPerWidth = defaultSymbolsPerWidth }
-func SetSymbolsCount(Count int)       { symbolsCount = Count }
-func SetDefaultSymbolsCount()         { symbolsCount = defaultSymbolsCount }
+// FontToAtlasWithOptions rasterizes the runes chosen by Opts into a white on
+// transparent image. Runes the font has no glyph for are left out. The
+// glyphs are shelf packed into the smallest power of two image that holds
+// them, and each Glyph records its UV rectangle.
+func FontToAtlasWithOptions(FontPath string, FontSize float32, Opts Options) (*image.RGBA, *FontAtlas, error) {
+	data, err := os.ReadFile(FontPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return FontDataToAtlas(data, FontSize, Opts)
+}
 
-func FontToAtlas(FontPath string, FontSize float32) (*image.RGBA, error) {
-	dpi := 72.0
-	symbolSize := int(FontSize * float32(dpi) / 72)
+// FontDataToAtlas is FontToAtlasWithOptions for a font file already in
+// memory, for example one embedded with go:embed.
+func FontDataToAtlas(Data []byte, FontSize float32, Opts Options) (*image.RGBA, *FontAtlas, error) {
+	if err := Opts.check(); err != nil {
+		return nil, nil, err
+	}
+	ttf, err := parseFont(Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, atlas := buildAtlas(Data, ttf, FontSize, Opts)
+	return img, atlas, nil
+}
 
-	width := symbolsPerWidth * symbolSize
-	height := (symbolsCount / symbolsPerWidth) * symbolSize
+// buildAtlas does the work of FontToAtlasWithOptions for a parsed font.
+func buildAtlas(data []byte, ttf *truetype.Font, FontSize float32, Opts Options) (*image.RGBA, *FontAtlas) {
+	face := truetype.NewFace(ttf, &truetype.Options{Size: float64(FontSize)})
+	defer face.Close()
+	atlas := &FontAtlas{
+		Metrics: fontMetrics(data, ttf, face, FontSize),
+		Glyphs:  map[rune]Glyph{},
+		SDF:     Opts.SDF,
+	}
+	var large font.Face
+	if Opts.SDF {
+		if Opts.Spread <= 0 {
+			Opts.Spread = defaultSpread
+		}
+		atlas.Spread = float32(Opts.Spread)
+		large = truetype.NewFace(ttf, &truetype.Options{Size: float64(FontSize * sdfUpscale)})
+		defer large.Close()
+	}
 
-	data, err := os.ReadFile(FontPath)
-	if err != nil {
-		return nil, err
+	padding := Opts.padding()
+	var runes []rune
+	var glyphs []Glyph
+	var masks []*image.Alpha
+	var sizes []image.Point
+	indices := map[truetype.Index][]rune{}
+	for _, r := range Opts.runes() {
+		var glyph Glyph
+		var mask *image.Alpha
+		var ok bool
+		if Opts.SDF {
+			glyph, mask, ok = rasterizeSDF(ttf, face, large, r, Opts.Spread)
+		} else {
+			glyph, mask, ok = rasterize(ttf, face, r)
+		}
+		if !ok {
+			continue
+		}
+		indices[ttf.Index(r)] = append(indices[ttf.Index(r)], r)
+		runes = append(runes, r)
+		glyphs = append(glyphs, glyph)
+		masks = append(masks, mask)
+		size := image.Point{}
+		if glyph.Width > 0 && glyph.Height > 0 {
+			size = image.Pt(glyph.Width+2*padding, glyph.Height+2*padding)
+		}
+		sizes = append(sizes, size)
+	}
+	atlas.Kerning = kerning(data, ttf, FontSize, indices)
+
+	var positions []image.Point
+	atlas.Width, atlas.Height, positions = packAll(sizes)
+	img := image.NewRGBA(image.Rect(0, 0, atlas.Width, atlas.Height))
+	for i, glyph := range glyphs {
+		if sizes[i] != (image.Point{}) {
+			glyph.X = positions[i].X + padding
+			glyph.Y = positions[i].Y + padding
+			drawGlyph(img, glyph, masks[i])
+		}
+		glyph.setUV(atlas.Width, atlas.Height)
+		atlas.Glyphs[runes[i]] = glyph
+	}
+	return img, atlas
+}
+
+func parseFont(Data []byte) (*truetype.Font, error) {
+	ttf, err := freetype.ParseFont(Data)
+	if _, ok := err.(truetype.UnsupportedError); ok {
+		return nil, fmt.Errorf("parse font: %w: %v", ErrUnsupportedFormat, err)
 	}
-	font, err := freetype.ParseFont(data)
 	if err != nil {
-		return nil, err
-	}
-
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-	ctx := freetype.NewContext()
-	ctx.SetFont(font)
-	ctx.SetFontSize(float64(FontSize))
-	ctx.SetDPI(dpi)
-	ctx.SetClip(img.Bounds())
-	ctx.SetDst(img)
-	ctx.SetSrc(image.White)
-	var y, x int
-	for i := 0; i < symbolsCount; i++ {
-		x = (i % symbolsPerWidth) * symbolSize
-		y = (i / symbolsPerWidth) * symbolSize
-		_, err = ctx.DrawString(string(rune(i)), freetype.Pt(x, y+symbolSize))
-	}
-	return img, nil
+		return nil, fmt.Errorf("parse font: %w: %v", ErrDecode, err)
+	}
+	return ttf, nil
+}
+
+func fontMetrics(Data []byte, Font *truetype.Font, Face font.Face, FontSize float32) Metrics {
+	metrics := Face.Metrics()
+	return Metrics{
+		FontSize: FontSize,
+		Ascent:   fixedToFloat(metrics.Ascent),
+		Descent:  fixedToFloat(metrics.Descent),
+		LineGap:  lineGap(Data, Font, FontSize),
+	}
+}
+
+// rasterize renders Rune at the origin and returns its metrics with a copy
+// of its coverage mask, or false when the font has no glyph for it.
+func rasterize(Font *truetype.Font, Face font.Face, Rune rune) (Glyph, *image.Alpha, bool) {
+	if Font.Index(Rune) == 0 {
+		return Glyph{}, nil, false
+	}
+	dr, mask, maskp, advance, ok := Face.Glyph(fixed.P(0, 0), Rune)
+	if !ok {
+		return Glyph{}, nil, false
+	}
+	// Face reuses its mask buffer, so the mask is copied out.
+	copied := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	draw.Draw(copied, copied.Bounds(), mask, maskp, draw.Src)
+	return Glyph{
+		Width:    dr.Dx(),
+		Height:   dr.Dy(),
+		BearingX: float32(dr.Min.X),
+		BearingY: float32(-dr.Min.Y),
+		Advance:  fixedToFloat(advance),
+	}, copied, true
+}
+
+// drawGlyph draws Mask white at the position of Glyph in Img.
+func drawGlyph(Img *image.RGBA, Glyph Glyph, Mask *image.Alpha) {
+	dr := image.Rect(Glyph.X, Glyph.Y, Glyph.X+Glyph.Width, Glyph.Y+Glyph.Height)
+	draw.DrawMask(Img, dr, image.White, image.Point{}, Mask, image.Point{}, draw.Over)
+}
+
+func fixedToFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
+
+// lineGap reads the line gap from the hhea table of the font file, which
+// truetype parses but does not expose.
+func lineGap(Data []byte, Font *truetype.Font, FontSize float32) float32 {
+	r := reader{data: table(Data, "hhea")}
+	gap := r.i16(8)
+	if r.bad {
+		return 0
+	}
+	return float32(math.Ceil(float64(gap) * float64(FontSize) / float64(Font.FUnitsPerEm())))
 }
@@ -0,0 +1,144 @@
+package ttf2atlas
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// Range is the Unicode code points First through Last, both included. A
+// Last past unicode.MaxRune stops at it.
+type Range struct {
+	First, Last rune
+}
+
+var (
+	BasicLatin       = Range{0x0020, 0x007E}
+	Latin1Supplement = Range{0x00A0, 0x00FF}
+	LatinExtendedA   = Range{0x0100, 0x017F}
+	LatinExtendedB   = Range{0x0180, 0x024F}
+	Greek            = Range{0x0370, 0x03FF}
+	Cyrillic         = Range{0x0400, 0x04FF}
+	Arabic           = Range{0x0600, 0x06FF}
+	Devanagari       = Range{0x0900, 0x097F}
+	Thai             = Range{0x0E00, 0x0E7F}
+	Punctuation      = Range{0x2000, 0x206F}
+	CurrencySymbols  = Range{0x20A0, 0x20CF}
+	Arrows           = Range{0x2190, 0x21FF}
+	MathOperators    = Range{0x2200, 0x22FF}
+	BoxDrawing       = Range{0x2500, 0x257F}
+	BlockElements    = Range{0x2580, 0x259F}
+	GeometricShapes  = Range{0x25A0, 0x25FF}
+	Hiragana         = Range{0x3040, 0x309F}
+	Katakana         = Range{0x30A0, 0x30FF}
+	CJKUnified       = Range{0x4E00, 0x9FFF}
+	Hangul           = Range{0xAC00, 0xD7AF}
+	Emoji            = Range{0x1F300, 0x1FAFF}
+)
+
+// DefaultRanges are the code points FontToAtlas has always covered, the
+// printable part of U+0000..U+07FF.
+var DefaultRanges = []Range{{0x0020, 0x07FF}}
+
+// Options choose what goes into an atlas. The runes of Ranges and Charset
+// are merged; when both are empty DefaultRanges is used. Padding is the
+// number of empty pixels kept around every glyph so filtering does not bleed
+// neighbours into each other, 1 when 0.
+//
+// With SDF set the atlas stores a signed distance field instead of coverage,
+// which stays sharp at any draw size. Spread is how many pixels the field
+// reaches past the outline, 4 when 0; outlines and shadows drawn from the
+// field cannot be wider than it.
+type Options struct {
+	Ranges  []Range
+	Charset string
+	Padding int
+	SDF     bool
+	Spread  int
+}
+
+// DefaultOptions returns the options FontToAtlas uses.
+func DefaultOptions() Options {
+	return Options{Ranges: DefaultRanges, Padding: 1}
+}
+
+// padding returns Padding, or 1 when it is not set.
+func (opts Options) padding() int {
+	if opts.Padding <= 0 {
+		return 1
+	}
+	return opts.Padding
+}
+
+// Normalized returns options that build the same atlas as opts, spelled
+// one way: the charset merged into sorted, non-overlapping ranges and the
+// default padding and spread filled in. Equal normalized options build equal
+// atlases. Options with an invalid range are returned as they are.
+func (opts Options) Normalized() Options {
+	if opts.check() != nil {
+		return opts
+	}
+	ranges := append([]Range{}, opts.Ranges...)
+	if len(ranges) == 0 && opts.Charset == "" {
+		ranges = append(ranges, DefaultRanges...)
+	}
+	for _, c := range opts.Charset {
+		ranges = append(ranges, Range{c, c})
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].First < ranges[j].First
+	})
+	out := Options{Padding: opts.padding(), SDF: opts.SDF}
+	for _, r := range ranges {
+		if r.Last > unicode.MaxRune {
+			r.Last = unicode.MaxRune
+		}
+		if n := len(out.Ranges); n > 0 && r.First <= out.Ranges[n-1].Last+1 {
+			if r.Last > out.Ranges[n-1].Last {
+				out.Ranges[n-1].Last = r.Last
+			}
+			continue
+		}
+		out.Ranges = append(out.Ranges, r)
+	}
+	if opts.SDF {
+		out.Spread = opts.Spread
+		if out.Spread <= 0 {
+			out.Spread = defaultSpread
+		}
+	}
+	return out
+}
+
+// runes returns the sorted, deduplicated code points the options ask for.
+func (opts Options) runes() []rune {
+	ranges := opts.Ranges
+	if len(ranges) == 0 && opts.Charset == "" {
+		ranges = DefaultRanges
+	}
+	set := map[rune]bool{}
+	for _, r := range ranges {
+		last := r.Last
+		if last > unicode.MaxRune {
+			last = unicode.MaxRune
+		}
+		for c := r.First; c <= last; c++ {
+			set[c] = true
+		}
+	}
+	for _, c := range opts.Charset {
+		set[c] = true
+	}
+	return sortedRunes(set)
+}
+
+// check returns an error for the first range that starts after its end or
+// outside Unicode.
+func (opts Options) check() error {
+	for _, r := range opts.Ranges {
+		if r.First < 0 || r.First > unicode.MaxRune || r.First > r.Last {
+			return fmt.Errorf("%w: %U..%U", ErrInvalidRange, r.First, r.Last)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package ttf2atlas
+
+import (
+	"errors"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestRunesClampLast(t *testing.T) {
+	runes := Options{Ranges: []Range{{unicode.MaxRune - 3, math.MaxInt32}}}.runes()
+	if len(runes) != 4 || runes[3] != unicode.MaxRune {
+		t.Fatalf("runes = %U, want the last 4 of Unicode", runes)
+	}
+}
+
+func TestInvalidRanges(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []Range{{'b', 'a'}, {-1, 'a'}, {unicode.MaxRune + 1, math.MaxInt32}} {
+		opts := Options{Ranges: []Range{BasicLatin, r}}
+		if err := opts.check(); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("check(%U..%U) = %v", r.First, r.Last, err)
+		}
+		if _, _, err := FontDataToAtlas(data, 16, opts); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("FontDataToAtlas(%U..%U) = %v", r.First, r.Last, err)
+		}
+		if _, _, err := FontDataToAtlasCached("vcr", data, 16, opts, t.TempDir()); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("FontDataToAtlasCached(%U..%U) = %v", r.First, r.Last, err)
+		}
+	}
+	if err := (Options{Ranges: []Range{{'a', 'a'}, {0x20, math.MaxInt32}}}).check(); err != nil {
+		t.Errorf("check = %v", err)
+	}
+}
+
+func TestZeroPaddingKeepsGap(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "Fonts", "Vcr.ttf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, atlas, err := FontDataToAtlas(data, 16, Options{Ranges: []Range{BasicLatin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rects []image.Rectangle
+	for _, glyph := range atlas.Glyphs {
+		if glyph.Width > 0 && glyph.Height > 0 {
+			rects = append(rects, image.Rect(glyph.X, glyph.Y, glyph.X+glyph.Width, glyph.Y+glyph.Height))
+		}
+	}
+	for i, a := range rects {
+		for _, b := range rects[i+1:] {
+			if a.Inset(-1).Overlaps(b) {
+				t.Fatalf("glyphs at %v and %v touch", a, b)
+			}
+		}
+	}
+}
+
+func TestNormalizedOptions(t *testing.T) {
+	want := Options{Ranges: []Range{{0x20, 0x7E}, {0xB0, 0xB1}}, Padding: 1}
+	for _, opts := range []Options{
+		{Ranges: []Range{BasicLatin}, Charset: "°±", Padding: 1},
+		{Ranges: []Range{{0x50, 0x7E}, {0x20, 0x60}}, Charset: "±°"},
+		{Charset: "±", Ranges: []Range{{0xB0, 0xB0}, {0x20, 0x4F}, {0x50, 0x7E}}, Spread: 6},
+	} {
+		if got := opts.Normalized(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%+v normalized to %+v, want %+v", opts, got, want)
+		}
+	}
+	if got := (Options{SDF: true}).Normalized(); got.Spread != defaultSpread || !reflect.DeepEqual(got.Ranges, DefaultRanges) {
+		t.Errorf("SDF defaults normalized to %+v", got)
+	}
+	invalid := Options{Ranges: []Range{{'b', 'a'}}}
+	if got := invalid.Normalized(); !reflect.DeepEqual(got, invalid) {
+		t.Errorf("invalid options normalized to %+v", got)
+	}
+}
@@ -0,0 +1,165 @@
+package ttf2atlas
+
+import (
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"math"
+)
+
+// sdfUpscale is how many times larger than the atlas glyphs are rasterized
+// before their distance field is computed, which keeps edges precise.
+const sdfUpscale = 4
+
+const defaultSpread = 4
+
+// rasterizeSDF renders Rune as a signed distance field. Every pixel holds
+// 0.5 on the outline, rising to 1 Spread pixels inside and falling to 0
+// Spread pixels outside; the field extends Spread pixels past the glyph on
+// every side so outlines and shadows have room. Face gives the advance at the
+// atlas size, Large is the same font sdfUpscale times bigger.
+func rasterizeSDF(Font *truetype.Font, Face, Large font.Face, Rune rune, Spread int) (Glyph, *image.Alpha, bool) {
+	if Font.Index(Rune) == 0 {
+		return Glyph{}, nil, false
+	}
+	advance, ok := Face.GlyphAdvance(Rune)
+	if !ok {
+		return Glyph{}, nil, false
+	}
+	dr, mask, maskp, _, ok := Large.Glyph(fixed.P(0, 0), Rune)
+	if !ok {
+		return Glyph{}, nil, false
+	}
+	if dr.Empty() {
+		return Glyph{Advance: fixedToFloat(advance)}, image.NewAlpha(image.Rectangle{}), true
+	}
+
+	// The glyph box at atlas size, grown by Spread.
+	box := image.Rect(
+		floorDiv(dr.Min.X, sdfUpscale)-Spread,
+		floorDiv(dr.Min.Y, sdfUpscale)-Spread,
+		-floorDiv(-dr.Max.X, sdfUpscale)+Spread,
+		-floorDiv(-dr.Max.Y, sdfUpscale)+Spread,
+	)
+	large := image.Rect(box.Min.X*sdfUpscale, box.Min.Y*sdfUpscale, box.Max.X*sdfUpscale, box.Max.Y*sdfUpscale)
+	width, height := large.Dx(), large.Dy()
+	inside := make([]bool, width*height)
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			_, _, _, a := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA()
+			inside[(y-large.Min.Y)*width+(x-large.Min.X)] = a >= 0x8000
+		}
+	}
+	toInside := distanceTransform(inside, width, height, true)
+	toOutside := distanceTransform(inside, width, height, false)
+
+	field := image.NewAlpha(image.Rect(0, 0, box.Dx(), box.Dy()))
+	for y := 0; y < box.Dy(); y++ {
+		for x := 0; x < box.Dx(); x++ {
+			// Average the four large pixels around the center of the small one.
+			var distance float64
+			for _, i := range [4]int{0, 1, width, width + 1} {
+				i += (y*sdfUpscale+sdfUpscale/2-1)*width + x*sdfUpscale + sdfUpscale/2 - 1
+				// Distances run between pixel centers, the edge is half a
+				// pixel closer.
+				d := math.Sqrt(toOutside[i]) - math.Sqrt(toInside[i])
+				if d > 0 {
+					d -= 0.5
+				} else {
+					d += 0.5
+				}
+				distance += d / 4
+			}
+			distance /= sdfUpscale
+			value := 0.5 + distance/float64(2*Spread)
+			if value < 0 {
+				value = 0
+			}
+			if value > 1 {
+				value = 1
+			}
+			field.Pix[y*field.Stride+x] = uint8(value*255 + 0.5)
+		}
+	}
+	return Glyph{
+		Width:    box.Dx(),
+		Height:   box.Dy(),
+		BearingX: float32(box.Min.X),
+		BearingY: float32(-box.Min.Y),
+		Advance:  fixedToFloat(advance),
+	}, field, true
+}
+
+// distanceTransform returns the squared distance from every pixel to the
+// nearest pixel whose Inside value equals Target, using the separable exact
+// transform of Felzenszwalb and Huttenlocher.
+func distanceTransform(Inside []bool, Width, Height int, Target bool) []float64 {
+	const infinity = 1e20
+	grid := make([]float64, Width*Height)
+	for i, in := range Inside {
+		if in == Target {
+			grid[i] = 0
+		} else {
+			grid[i] = infinity
+		}
+	}
+	size := Width
+	if Height > size {
+		size = Height
+	}
+	f := make([]float64, size)
+	d := make([]float64, size)
+	v := make([]int, size)
+	z := make([]float64, size+1)
+	for x := 0; x < Width; x++ {
+		for y := 0; y < Height; y++ {
+			f[y] = grid[y*Width+x]
+		}
+		transform1D(f[:Height], d[:Height], v, z)
+		for y := 0; y < Height; y++ {
+			grid[y*Width+x] = d[y]
+		}
+	}
+	for y := 0; y < Height; y++ {
+		copy(f, grid[y*Width:(y+1)*Width])
+		transform1D(f[:Width], d[:Width], v, z)
+		copy(grid[y*Width:(y+1)*Width], d[:Width])
+	}
+	return grid
+}
+
+// transform1D computes the lower envelope of the parabolas rooted at F into D.
+func transform1D(F, D []float64, v []int, z []float64) {
+	n := len(F)
+	k := 0
+	v[0] = 0
+	z[0] = math.Inf(-1)
+	z[1] = math.Inf(1)
+	for q := 1; q < n; q++ {
+		s := ((F[q] + float64(q*q)) - (F[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+		for s <= z[k] {
+			k--
+			s = ((F[q] + float64(q*q)) - (F[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+		}
+		k++
+		v[k] = q
+		z[k] = s
+		z[k+1] = math.Inf(1)
+	}
+	k = 0
+	for q := 0; q < n; q++ {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		D[q] = float64((q-v[k])*(q-v[k])) + F[v[k]]
+	}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,23 @@
+package ttf2atlas_test
+
+import (
+	"DrawerGO/Overlay"
+	"DrawerGO/Overlay/Golden"
+	"DrawerGO/Overlay/ttf2atlas"
+	"testing"
+)
+
+func TestSDFTextGolden(t *testing.T) {
+	Golden.Run(t, Golden.Options{Tolerance: 2}, Golden.Case{
+		Name: "sdf_text", Width: 192, Height: 96,
+		Draw: func(t testing.TB, app *Overlay.App, assets Golden.Assets) {
+			font, err := app.LoadFontWithOptions(assets.FontPath, 16, ttf2atlas.Options{Ranges: []ttf2atlas.Range{ttf2atlas.BasicLatin}, Padding: 1, SDF: true})
+			Golden.Must(t, err)
+			app.SetColor(255, 255, 255, 255)
+			app.DrawText(4, 4, 12, 0, 0, font, 1, "Small")
+			app.SetTextOutline(2, 0, 0, 255, 255)
+			app.SetTextShadow(3, 3, 2, 0, 0, 0, 160)
+			app.DrawText(4, 24, 48, 0, 0, font, 1, "SDF")
+		},
+	})
+}
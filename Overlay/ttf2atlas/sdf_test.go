@@ -0,0 +1,73 @@
+package ttf2atlas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDistanceTransform(t *testing.T) {
+	inside := []bool{
+		false, false, false, false,
+		false, true, false, false,
+		false, false, false, true,
+	}
+	toInside := distanceTransform(inside, 4, 3, true)
+	want := []float64{
+		2, 1, 2, 4,
+		1, 0, 1, 1,
+		2, 1, 1, 0,
+	}
+	if !reflect.DeepEqual(toInside, want) {
+		t.Errorf("squared distances to inside = %v, want %v", toInside, want)
+	}
+	toOutside := distanceTransform(inside, 4, 3, false)
+	want = []float64{
+		0, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 0, 1,
+	}
+	if !reflect.DeepEqual(toOutside, want) {
+		t.Errorf("squared distances to outside = %v, want %v", toOutside, want)
+	}
+}
+
+// TestSDFField samples the field of a dash with a spread of 2, where one
+// pixel of distance moves the value by 255/4.
+func TestSDFField(t *testing.T) {
+	img, atlas, err := FontDataToAtlas(readVcr(t), 16, Options{Ranges: []Range{{First: '-', Last: '-'}}, SDF: true, Spread: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if atlas.Spread != 2 {
+		t.Errorf("spread = %g, want 2", atlas.Spread)
+	}
+	glyph := atlas.Glyphs['-']
+	if glyph.Width != 11 || glyph.Height != 7 || glyph.BearingX != -1 || glyph.BearingY != 10 {
+		t.Fatalf("glyph = %+v, want 11x7 with the spread around it", glyph)
+	}
+	sample := func(X, Y int) uint8 {
+		return img.RGBAAt(glyph.X+X, glyph.Y+Y).A
+	}
+
+	// Down the middle: 2 pixels outside and more clamp to 0, 0.5 at the
+	// edge, and half a pixel inside is 0.5 + 0.5/4.
+	var column []uint8
+	for y := 0; y < glyph.Height; y++ {
+		column = append(column, sample(5, y))
+	}
+	if want := []uint8{0, 32, 96, 159, 128, 64, 0}; !reflect.DeepEqual(column, want) {
+		t.Errorf("middle column = %v, want %v", column, want)
+	}
+	var row []uint8
+	for x := 0; x < 4; x++ {
+		row = append(row, sample(x, 3))
+	}
+	if want := []uint8{0, 64, 128, 159}; !reflect.DeepEqual(row, want) {
+		t.Errorf("left of the middle row = %v, want %v", row, want)
+	}
+	for x := 0; x < glyph.Width; x++ {
+		if top, bottom := sample(x, 0), sample(x, glyph.Height-1); top != 0 || bottom != 0 {
+			t.Errorf("column %d is %d at the top and %d at the bottom of the spread, want 0", x, top, bottom)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+//go:build windows
+
+package Overlay
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/gonutz/w32/v2"
+	"unsafe"
+)
+
+// The overlay window needs GLFW and the Win32 API, so it only builds on
+// Windows. Everything else, including NewHeadless and SoftwareRenderer,
+// builds anywhere without cgo.
+
+// New opens the transparent overlay window and sets up OpenGL in it. The
+// error is an *InitError naming the step that failed; when the shaders do
+// not build it wraps a *GlTools.ShaderError.
+func New() (App, error) {
+	window, err := newWindow("DrawerOverlayWindow")
+	if err != nil {
+		return App{}, err
+	}
+	version, err := initGl()
+	if err != nil {
+		glfw.Terminate()
+		return App{}, &InitError{Op: "init gl", Err: err}
+	}
+	renderer := newGlRenderer(window.glfwWindow, window.hwnd, version)
+	if err := renderer.Init(); err != nil {
+		renderer.Dispose()
+		glfw.Terminate()
+		return App{}, &InitError{Op: "init renderer", Err: err}
+	}
+	ctx := newContext(renderer)
+
+	return App{
+		context: ctx,
+		window:  window,
+		state:   newDrawState(ctx.drawList.layer("")),
+		isRun:   true,
+	}, nil
+}
+
+type Window struct {
+	name       string
+	hwnd       w32.HWND
+	glfwWindow *glfw.Window
+}
+
+func (window *Window) isOpen() bool {
+	return window.glfwWindow != nil
+}
+func (window *Window) shouldClose() bool {
+	return window.glfwWindow.ShouldClose()
+}
+func (window *Window) close() {
+	glfw.Terminate()
+}
+func (window *Window) cursorPos() (float32, float32) {
+	x, y := window.glfwWindow.GetCursorPos()
+	return float32(x), float32(y)
+}
+func (window *Window) size() (int, int) {
+	x, y := window.glfwWindow.GetSize()
+	y -= 1
+	return x, y
+}
+
+// GetGLVersion returns the OpenGL version string of the driver, or "" for a
+// headless App.
+func (app *App) GetGLVersion() string {
+	if renderer, ok := app.context.renderer.(*glRenderer); ok {
+		return renderer.version
+	}
+	return ""
+}
+func (app *App) IsKeyDown(Key int) bool {
+	return w32.GetKeyState(int(Key)) > 1
+}
+func (app *App) IsMouseButton1Down() bool {
+	return w32.GetAsyncKeyState(w32.VK_LBUTTON) > 1
+}
+func (app *App) IsMouseButton2Down() bool {
+	return w32.GetAsyncKeyState(w32.VK_RBUTTON) > 1
+}
+func (app *App) IsShiftDown() bool {
+	return w32.GetAsyncKeyState(w32.VK_SHIFT) > 1
+}
+func (app *App) IsCtrlDown() bool {
+	return w32.GetAsyncKeyState(w32.VK_CONTROL) > 1
+}
+func (app *App) IsBackspaceDown() bool {
+	return w32.GetAsyncKeyState(w32.VK_BACK) > 1
+}
+func (app *App) IsEnterDown() bool {
+	return w32.GetAsyncKeyState(w32.VK_RETURN) > 1
+}
+
+func GetDisplaySize() (int32, int32) {
+	hMonitor := w32.MonitorFromPoint(0, 0, w32.MONITOR_DEFAULTTOPRIMARY)
+
+	var monitorInfo w32.MONITORINFO
+	monitorInfo.CbSize = uint32(unsafe.Sizeof(monitorInfo))
+	w32.GetMonitorInfo(hMonitor, &monitorInfo)
+
+	width := monitorInfo.RcMonitor.Right - monitorInfo.RcMonitor.Left
+	height := monitorInfo.RcMonitor.Bottom - monitorInfo.RcMonitor.Top
+	return width, height
+}
+
+func initGlfw(name string) (*glfw.Window, w32.HWND, error) {
+	if err := glfw.Init(); err != nil {
+		return nil, 0, &InitError{Op: "init glfw", Err: err}
+	}
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 6)
+	glfw.WindowHint(glfw.Samples, 2)
+	glfw.WindowHint(glfw.Decorated, glfw.False)
+	glfw.WindowHint(glfw.TransparentFramebuffer, glfw.True)
+	glfw.WindowHint(glfw.Focused, glfw.False)
+	glfw.WindowHint(glfw.FocusOnShow, glfw.False)
+
+	w, h := GetDisplaySize()
+	window, err := glfw.CreateWindow(int(w), int(h+1), name, nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		return nil, 0, &InitError{Op: "create window", Err: err}
+	}
+	window.MakeContextCurrent()
+	window.SetAttrib(glfw.Floating, glfw.True)
+
+	window.Focus()
+	hwnd := w32.GetActiveWindow()
+	w32.SetWindowLong(
+		hwnd,
+		w32.GWL_EXSTYLE,
+		w32.WS_EX_TRANSPARENT|w32.WS_EX_LAYERED|w32.WS_EX_TOOLWINDOW,
+	)
+	w32.SetWindowPos(
+		hwnd,
+		w32.HWND_TOPMOST,
+		0, 0, 0, 0,
+		w32.SWP_NOMOVE|w32.SWP_NOSIZE,
+	)
+	window.Show()
+
+	return window, hwnd, nil
+}
+
+func newWindow(name string) (Window, error) {
+	win, hwnd, err := initGlfw(name)
+	if err != nil {
+		return Window{}, err
+	}
+	glfw.SwapInterval(0)
+	return Window{
+		name:       name,
+		glfwWindow: win,
+		hwnd:       hwnd,
+	}, nil
+}